@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/session"
+	"be-binareversi/model"
+	"crypto/rand"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// トークンの有効期間
+var SessionTTL = 24 * time.Hour
+
+// WebSocket でトークンを渡すときのサブプロトコル名（["bearer", "<token>"] の順で指定）
+const Subprotocol = "bearer"
+
+var (
+	ErrNoToken        = errors.New("missing session token")
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+var secret []byte
+
+// 署名鍵を設定する（空の場合はランダムな鍵を生成）
+func SetSecret(s string) {
+	if s != "" {
+		secret = []byte(s)
+		return
+	}
+	secret = make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("failed to generate session secret: %v", err)
+	}
+	log.Println("SESSION_SECRET is not set; using a random key (sessions will not survive restarts).")
+}
+
+// プレイヤーに新しいセッションを発行する
+// @param playerID 対象のプレイヤー
+// @return string トークン
+// @return time.Time 有効期限
+func IssueToken(playerID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(SessionTTL)
+	s := &model.Session{
+		ID:        uuid.New().String(),
+		PlayerID:  playerID,
+		ExpiresAt: expiresAt,
+	}
+	if err := db.CreateSession(s); err != nil {
+		return "", time.Time{}, err
+	}
+
+	token, err := session.Sign(secret, session.Claims{
+		PlayerID:  playerID,
+		SessionID: s.ID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// トークンを検証し、対応するプレイヤーを返す
+// @param token トークン文字列
+// @return *model.Player プレイヤー
+// @return session.Claims トークンの内容
+// @return error 不正・期限切れ・失効済みの場合はエラー
+func Authenticate(token string) (*model.Player, session.Claims, error) {
+	claims, err := session.Parse(secret, token, time.Now())
+	if err != nil {
+		return nil, claims, err
+	}
+	s, err := db.GetSessionByID(claims.SessionID)
+	if err != nil || s.PlayerID != claims.PlayerID {
+		return nil, claims, session.ErrInvalidToken
+	}
+	if s.RevokedAt != nil {
+		return nil, claims, ErrSessionRevoked
+	}
+	player, err := db.GetPlayerByID(claims.PlayerID)
+	if err != nil {
+		return nil, claims, session.ErrInvalidToken
+	}
+
+	// 利用のたびに最終利用日時を更新（非アクティブ削除の対象から外す）
+	if time.Since(player.LastUsedAt) > time.Minute {
+		player.LastUsedAt = time.Now()
+		db.TouchPlayer(player.ID)
+	}
+	return player, claims, nil
+}
+
+// リクエストからトークンを取り出す
+// Authorization: Bearer <token> または Sec-WebSocket-Protocol: bearer, <token>
+func TokenFromRequest(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	}
+
+	var protocols []string
+	for _, h := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(h, ",") {
+			protocols = append(protocols, strings.TrimSpace(p))
+		}
+	}
+	for i, p := range protocols {
+		if p == Subprotocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
+
+// リクエストを認証する
+func AuthenticateRequest(r *http.Request) (*model.Player, session.Claims, error) {
+	token := TokenFromRequest(r)
+	if token == "" {
+		return nil, session.Claims{}, ErrNoToken
+	}
+	return Authenticate(token)
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"be-binareversi/libs/session"
+	"be-binareversi/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	playerKey = "player"
+	claimsKey = "claims"
+)
+
+// 有効なセッションを必須とするミドルウェア
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		player, claims, err := AuthenticateRequest(c.Request)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.Set(playerKey, player)
+		c.Set(claimsKey, claims)
+		c.Next()
+	}
+}
+
+// 認証済みのプレイヤーを返す
+func CurrentPlayer(c *gin.Context) *model.Player {
+	return c.MustGet(playerKey).(*model.Player)
+}
+
+// 認証に使ったトークンの内容を返す
+func CurrentClaims(c *gin.Context) session.Claims {
+	return c.MustGet(claimsKey).(session.Claims)
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"be-binareversi/model"
+)
+
+// チャットの発言を保存
+func CreateChatMessage(msg *model.ChatMessage) error {
+	return Chats.Create(msg)
+}
+
+// ルームの発言を古い順に取得（roomID が空ならロビー）
+func GetChatMessages(roomID string, limit int) ([]*model.ChatMessage, error) {
+	return Chats.ListRecent(roomID, limit)
+}
@@ -1,49 +1,106 @@
 package db
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
-	"be-binareversi/model"
+	"be-binareversi/migration"
+	"be-binareversi/repository"
 )
 
-var DB *gorm.DB
+// 各リポジトリ（InitDatabase で GORM 実装が設定される）
+var (
+	Players  repository.PlayerRepository
+	Rooms    repository.RoomRepository
+	Games    repository.GameRepository
+	Sessions repository.SessionRepository
+	Ratings  repository.RatingRepository
+	Chats    repository.ChatRepository
+)
 
-func InitDatabase() {
-	const dbPath = "data/rooms.db"
+// 対応しているデータベース
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
 
-	// ディレクトリが存在しない場合は作成
-	if err := os.MkdirAll("data", os.ModePerm); err != nil {
-		log.Fatalf("failed to create data directory: %v", err)
+const defaultSQLitePath = "data/rooms.db"
+
+// 接続設定
+type Config struct {
+	Driver string // sqlite / postgres
+	DSN    string // sqlite ならファイルパス、postgres なら接続文字列
+}
+
+// 環境変数 DB_DRIVER / DB_DSN から接続設定を読み取る（未指定なら data/rooms.db の SQLite）
+func ConfigFromEnv() Config {
+	cfg := Config{Driver: os.Getenv("DB_DRIVER"), DSN: os.Getenv("DB_DSN")}
+	if cfg.Driver == "" {
+		cfg.Driver = DriverSQLite
+	}
+	if cfg.Driver == DriverSQLite && cfg.DSN == "" {
+		cfg.DSN = defaultSQLitePath
 	}
+	return cfg
+}
 
-	// DBファイルが存在しない場合は作成
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		file, err := os.Create(dbPath)
-		if err != nil {
-			log.Fatalf("failed to create db file: %v", err)
+// 設定に応じたデータベースに接続する
+// @param cfg 接続設定
+// @return *gorm.DB 接続
+func Open(cfg Config) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case DriverSQLite:
+		// ディレクトリが存在しない場合は作成（DBファイルは sqlite が作成する）
+		if dir := filepath.Dir(cfg.DSN); dir != "." {
+			if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+				return nil, fmt.Errorf("failed to create data directory: %w", err)
+			}
+		}
+		return gorm.Open(sqlite.Open(cfg.DSN), &gorm.Config{})
+	case DriverPostgres:
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("DB_DSN is required for %s", cfg.Driver)
 		}
-		file.Close()
-		log.Println("Database file created.")
+		return gorm.Open(postgres.Open(cfg.DSN), &gorm.Config{})
 	}
+	return nil, fmt.Errorf("unsupported database driver: %q", cfg.Driver)
+}
+
+// 未適用のマイグレーションを全て適用する
+func Migrate(database *gorm.DB) error {
+	applied, err := migration.New(database).Up(0)
+	for _, mig := range applied {
+		log.Printf("Applied migration %04d_%s", mig.Version, mig.Name)
+	}
+	return err
+}
+
+// リポジトリを差し替える（テストでメモリ実装を使う場合など）
+func SetRepositories(repos repository.Repositories) {
+	Players, Rooms, Games = repos.Players, repos.Rooms, repos.Games
+	Sessions, Ratings, Chats = repos.Sessions, repos.Ratings, repos.Chats
+}
+
+func InitDatabase() {
+	cfg := ConfigFromEnv()
 
 	// DB接続
-	database, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	database, err := Open(cfg)
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
 
 	// マイグレーション
-	if err := database.AutoMigrate(
-		&model.Room{},
-		&model.Player{},
-	); err != nil {
+	if err := Migrate(database); err != nil {
 		log.Fatalf("failed to migrate models: %v", err)
 	}
 
-	DB = database
-	log.Println("Database initialized and migrated.")
+	SetRepositories(repository.NewGorm(database))
+	log.Printf("Database (%s) initialized and migrated.", cfg.Driver)
 }
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"be-binareversi/model"
+)
+
+func CreateGameRecord(record *model.GameRecord) error {
+	return Games.Create(record)
+}
+
+// プレイヤーの対局記録を新しい順に取得（limit が負なら全件）
+func GetGameRecordsByPlayer(playerID string, limit, offset int) ([]*model.GameRecord, error) {
+	return Games.ListByPlayer(playerID, limit, offset)
+}
+
+func CountGameRecordsByPlayer(playerID string) (int64, error) {
+	return Games.CountByPlayer(playerID)
+}
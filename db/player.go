@@ -7,31 +7,45 @@ import (
 
 // プレイヤーを作成
 func CreatePlayer(player *model.Player) error {
-	return DB.Create(player).Error
+	return Players.Create(player)
 }
 
 // IDでプレイヤーを取得
 func GetPlayerByID(id string) (*model.Player, error) {
-	var player model.Player
-	err := DB.First(&player, "id = ?", id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &player, nil
+	return Players.GetByID(id)
+}
+
+// ユーザー名でプレイヤーを取得
+func GetPlayerByUsername(username string) (*model.Player, error) {
+	return Players.GetByUsername(username)
 }
 
 // プレイヤー情報を更新
 func UpdatePlayer(player *model.Player) error {
-	return DB.Save(player).Error
+	return Players.Update(player)
+}
+
+// 最終利用日時だけを現在時刻に更新
+func TouchPlayer(id string) error {
+	return Players.Touch(id, time.Now())
+}
+
+// 表示名だけを更新
+func RenamePlayer(id, name string) error {
+	return Players.Rename(id, name, time.Now())
+}
+
+// ゲストプレイヤーにユーザー名とパスワードハッシュを設定
+func SetPlayerCredentials(id, username, passwordHash string) error {
+	return Players.SetCredentials(id, username, passwordHash, time.Now())
 }
 
 // プレイヤーを削除
 func DeletePlayer(id string) error {
-	return DB.Delete(&model.Player{}, "id = ?", id).Error
+	return Players.Delete(id)
 }
 
-// 一定期間アクセスされていないプレイヤーを削除
+// 一定期間アクセスされていないゲストプレイヤーを削除（アカウント登録済みは対象外）
 func DeleteInactivePlayers(thresholdMinutes int) error {
-	threshold := time.Now().Add(-time.Duration(thresholdMinutes) * time.Minute)
-	return DB.Where("last_used_at < ?", threshold).Delete(&model.Player{}).Error
+	return Players.DeleteInactive(time.Now().Add(-time.Duration(thresholdMinutes) * time.Minute))
 }
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"be-binareversi/model"
+	"be-binareversi/repository"
+)
+
+// 対局結果によるレーティング更新を1トランザクションで保存
+// 読み込みから書き込みまでをまとめて行い、同時に終わった対局の更新を失わない
+// @param playerIDs 対象のプレイヤー
+// @param compute 読み込んだプレイヤー（playerIDs の順）のレーティングを書き換え、保存する履歴を返す関数（空なら何も保存しない）
+// @return error 読み込みか保存に失敗すればエラー
+func SaveRatingResult(playerIDs []string, compute repository.RatingCompute) error {
+	return Ratings.Save(playerIDs, compute)
+}
+
+// レーティング順にプレイヤーを取得（AIと未対局のプレイヤーは除く）
+func GetLeaderboard(limit, offset int) ([]*model.Player, error) {
+	return Ratings.Leaderboard(limit, offset)
+}
+
+// プレイヤーのレーティング履歴を新しい順に取得
+func GetRatingHistory(playerID string, limit, offset int) ([]*model.RatingHistory, error) {
+	return Ratings.History(playerID, limit, offset)
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"be-binareversi/model"
+	"time"
+)
+
+func CreateSession(session *model.Session) error {
+	return Sessions.Create(session)
+}
+
+func GetSessionByID(id string) (*model.Session, error) {
+	return Sessions.GetByID(id)
+}
+
+// セッションを失効させる
+func RevokeSession(id string) error {
+	return Sessions.Revoke(id, time.Now())
+}
+
+// プレイヤーの全セッションを失効させる
+func RevokePlayerSessions(playerID string) error {
+	return Sessions.RevokeByPlayer(playerID, time.Now())
+}
+
+// 期限切れのセッションを削除
+func DeleteExpiredSessions() error {
+	return Sessions.DeleteExpired(time.Now())
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"be-binareversi/auth"
+	"be-binareversi/db"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,20}$`)
+
+const minPasswordLength = 8
+
+type AccountRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ゲストプレイヤーをアカウントに昇格する（ID を引き継ぐので対局記録とレーティングもそのまま）
+func UpgradeAccount(c *gin.Context) {
+	player := auth.CurrentPlayer(c)
+	if player.Username != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "already upgraded"})
+		return
+	}
+
+	var req AccountRequest
+	if err := c.BindJSON(&req); err != nil || !usernamePattern.MatchString(req.Username) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid username"})
+		return
+	}
+	if len(req.Password) < minPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password too short"})
+		return
+	}
+	if _, err := db.GetPlayerByUsername(req.Username); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upgrade account"})
+		return
+	}
+	if err := db.SetPlayerCredentials(player.ID, req.Username, string(hash)); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"userID": player.ID, "name": player.Name, "username": req.Username})
+}
+
+// ユーザー名とパスワードでログインし、セッションを発行する
+func Login(c *gin.Context) {
+	var req AccountRequest
+	if err := c.BindJSON(&req); err != nil || req.Username == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	player, err := db.GetPlayerByUsername(req.Username)
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(player.PasswordHash), []byte(req.Password)) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	token, expiresAt, err := auth.IssueToken(player.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue session"})
+		return
+	}
+	db.TouchPlayer(player.ID)
+
+	c.JSON(http.StatusOK, RegisterResponse{UserID: player.ID, Name: player.Name, Token: token, ExpiresAt: &expiresAt})
+}
@@ -1,15 +1,27 @@
 package handler
 
 import (
+	"be-binareversi/auth"
 	"be-binareversi/websocket"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 func GameWebSocket(c *gin.Context) {
 	roomID := c.Param("roomID")
-	playerID := c.Param("playerID")
+
+	// プレイヤーはURLではなくセッショントークンから特定する
+	player, _, err := auth.AuthenticateRequest(c.Request)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if playerID := c.Param("playerID"); playerID != "" && playerID != player.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "playerID does not match session"})
+		return
+	}
 
 	// WebSocketハンドラーにplayerIDを渡す
-	websocket.HandleGame(roomID, playerID, c.Writer, c.Request)
+	websocket.HandleGame(roomID, player.ID, c.Writer, c.Request)
 }
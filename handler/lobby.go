@@ -1,11 +1,18 @@
 package handler
 
 import (
+	"be-binareversi/auth"
 	"be-binareversi/websocket"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 func LobbyWebSocket(c *gin.Context) {
-	websocket.HandleLobby(c.Writer, c.Request)
+	player, _, err := auth.AuthenticateRequest(c.Request)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	websocket.HandleLobby(player.ID, c.Writer, c.Request)
 }
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"be-binareversi/auth"
+	"be-binareversi/db"
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OperatorUsage struct {
+	Plus     int    `json:"plus"`
+	Mul      int    `json:"mul"`
+	Favorite string `json:"favorite,omitempty"` // "+" / "*"（未使用なら空）
+}
+
+type PlayerProfile struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Username      *string       `json:"username,omitempty"`
+	Rating        float64       `json:"rating"`
+	RatingRD      float64       `json:"ratingRD"`
+	Provisional   bool          `json:"provisional"`
+	GamesPlayed   int           `json:"gamesPlayed"`
+	Wins          int           `json:"wins"`
+	Losses        int           `json:"losses"`
+	Draws         int           `json:"draws"`
+	AverageMargin float64       `json:"averageMargin"` // 自分の石数 - 相手の石数 の平均
+	OperatorUsage OperatorUsage `json:"operatorUsage"`
+}
+
+type GameSummary struct {
+	ID            uint      `json:"id"`
+	RoomID        string    `json:"roomID"`
+	OpponentID    string    `json:"opponentID"`
+	OpponentName  string    `json:"opponentName"`
+	YourColor     int       `json:"yourColor"`
+	Result        string    `json:"result"` // win / loss / draw
+	Reason        string    `json:"reason"`
+	YourDiscs     int       `json:"yourDiscs"`
+	OpponentDiscs int       `json:"opponentDiscs"`
+	StartedAt     time.Time `json:"startedAt"`
+	EndedAt       time.Time `json:"endedAt"`
+}
+
+type UpdatePlayerRequest struct {
+	Name string `json:"name"`
+}
+
+// 対局記録をプレイヤー視点の要約に変換する
+func summarize(record *model.GameRecord, playerID string) GameSummary {
+	s := GameSummary{
+		ID:        record.ID,
+		RoomID:    record.RoomID,
+		Reason:    record.Reason,
+		StartedAt: record.StartedAt,
+		EndedAt:   record.EndedAt,
+	}
+	if record.BlackID == playerID {
+		s.YourColor = reversi.Black
+		s.OpponentID = record.WhiteID
+		s.YourDiscs, s.OpponentDiscs = record.BlackDiscs, record.WhiteDiscs
+	} else {
+		s.YourColor = reversi.White
+		s.OpponentID = record.BlackID
+		s.YourDiscs, s.OpponentDiscs = record.WhiteDiscs, record.BlackDiscs
+	}
+	switch record.Winner {
+	case s.YourColor:
+		s.Result = "win"
+	case -1:
+		s.Result = "draw"
+	default:
+		s.Result = "loss"
+	}
+	return s
+}
+
+func GetPlayerProfile(c *gin.Context) {
+	player, err := db.GetPlayerByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "player not found"})
+		return
+	}
+
+	records, err := db.GetGameRecordsByPlayer(player.ID, -1, -1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load games"})
+		return
+	}
+
+	profile := PlayerProfile{
+		ID:          player.ID,
+		Name:        player.Name,
+		Username:    player.Username,
+		Rating:      player.Rating,
+		RatingRD:    player.RatingRD,
+		Provisional: isProvisional(player),
+		GamesPlayed: len(records),
+	}
+	marginSum := 0
+	for _, record := range records {
+		s := summarize(record, player.ID)
+		switch s.Result {
+		case "win":
+			profile.Wins++
+		case "loss":
+			profile.Losses++
+		default:
+			profile.Draws++
+		}
+		marginSum += s.YourDiscs - s.OpponentDiscs
+
+		if s.YourColor == reversi.Black {
+			profile.OperatorUsage.Plus += record.BlackPlus
+			profile.OperatorUsage.Mul += record.BlackMul
+		} else {
+			profile.OperatorUsage.Plus += record.WhitePlus
+			profile.OperatorUsage.Mul += record.WhiteMul
+		}
+	}
+	if len(records) > 0 {
+		profile.AverageMargin = float64(marginSum) / float64(len(records))
+	}
+	if usage := &profile.OperatorUsage; usage.Plus > 0 || usage.Mul > 0 {
+		usage.Favorite = "+"
+		if usage.Mul > usage.Plus {
+			usage.Favorite = "*"
+		}
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func UpdatePlayerProfile(c *gin.Context) {
+	player := auth.CurrentPlayer(c)
+	if player.ID != c.Param("id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "cannot update another player"})
+		return
+	}
+
+	var req UpdatePlayerRequest
+	if err := c.BindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid name"})
+		return
+	}
+
+	player.Name = strings.TrimSpace(req.Name)
+	if err := db.RenamePlayer(player.ID, player.Name); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update player"})
+		return
+	}
+	c.JSON(http.StatusOK, RegisterResponse{UserID: player.ID, Name: player.Name})
+}
+
+func GetPlayerGames(c *gin.Context) {
+	playerID := c.Param("id")
+	if _, err := db.GetPlayerByID(playerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "player not found"})
+		return
+	}
+
+	limit, offset := pagination(c)
+	records, err := db.GetGameRecordsByPlayer(playerID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load games"})
+		return
+	}
+	total, _ := db.CountGameRecordsByPlayer(playerID)
+
+	games := []GameSummary{}
+	names := map[string]string{}
+	for _, record := range records {
+		s := summarize(record, playerID)
+		if _, ok := names[s.OpponentID]; !ok {
+			if opponent, err := db.GetPlayerByID(s.OpponentID); err == nil {
+				names[s.OpponentID] = opponent.Name
+			}
+		}
+		s.OpponentName = names[s.OpponentID]
+		games = append(games, s)
+	}
+	c.JSON(http.StatusOK, gin.H{"games": games, "total": total, "limit": limit, "offset": offset})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/glicko2"
+	"be-binareversi/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LeaderboardEntry struct {
+	Rank        int     `json:"rank"`
+	PlayerID    string  `json:"playerID"`
+	Name        string  `json:"name"`
+	Rating      float64 `json:"rating"`
+	RatingRD    float64 `json:"ratingRD"`
+	RatedGames  int     `json:"ratedGames"`
+	Provisional bool    `json:"provisional"`
+}
+
+// limit / offset クエリを読み取る（limit は 1〜100）
+func pagination(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func isProvisional(p *model.Player) bool {
+	return glicko2.Rating{Rating: p.Rating, RD: p.RatingRD, Volatility: p.Volatility}.Provisional()
+}
+
+func GetLeaderboard(c *gin.Context) {
+	limit, offset := pagination(c)
+
+	players, err := db.GetLeaderboard(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load leaderboard"})
+		return
+	}
+
+	entries := []LeaderboardEntry{}
+	for i, p := range players {
+		entries = append(entries, LeaderboardEntry{
+			Rank:        offset + i + 1,
+			PlayerID:    p.ID,
+			Name:        p.Name,
+			Rating:      p.Rating,
+			RatingRD:    p.RatingRD,
+			RatedGames:  p.RatedGames,
+			Provisional: isProvisional(p),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"players": entries, "limit": limit, "offset": offset})
+}
+
+func GetRatingHistory(c *gin.Context) {
+	playerID := c.Param("id")
+	if _, err := db.GetPlayerByID(playerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "player not found"})
+		return
+	}
+
+	limit, offset := pagination(c)
+	history, err := db.GetRatingHistory(playerID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load rating history"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history": history, "limit": limit, "offset": offset})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"be-binareversi/auth"
+	"be-binareversi/service/lobby"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ルーム操作の業務ロジック（main で設定する）
+var Rooms lobby.Service
+
+// ルーム操作のエラーを HTTP ステータスに変換する
+func roomErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, lobby.ErrRoomNotFound), errors.Is(err, lobby.ErrRoomFull):
+		return http.StatusNotFound
+	case errors.Is(err, lobby.ErrNotRoomHost),
+		errors.Is(err, lobby.ErrRoomAccessDenied),
+		errors.Is(err, lobby.ErrRoomReserved):
+		return http.StatusForbidden
+	case errors.Is(err, lobby.ErrUnknownVariant),
+		errors.Is(err, lobby.ErrInvalidControl),
+		errors.Is(err, lobby.ErrUnknownColorMode),
+		errors.Is(err, lobby.ErrInvalidOpening),
+		errors.Is(err, lobby.ErrInvalidBoardSize),
+		errors.Is(err, lobby.ErrInvalidPlayer),
+		errors.Is(err, lobby.ErrInvalidSort),
+		errors.Is(err, lobby.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, lobby.ErrRoomInProgress):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func ListRooms(c *gin.Context) {
+	limit, offset := pagination(c)
+	status := c.Query("status")
+	if status != "" && status != "open" && status != "full" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open or full"})
+		return
+	}
+
+	var ratings [2]float64
+	for i, key := range []string{"minRating", "maxRating"} {
+		if v := c.Query(key); v != "" {
+			rating, err := strconv.ParseFloat(v, 64)
+			if err != nil || rating < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": key + " must be a non-negative number"})
+				return
+			}
+			ratings[i] = rating
+		}
+	}
+
+	page, err := Rooms.ListRooms(lobby.RoomFilter{
+		Status:    status,
+		Variant:   c.Query("variant"),
+		MinRating: ratings[0],
+		MaxRating: ratings[1],
+		Sort:      c.Query("sort"),
+		Cursor:    c.Query("cursor"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		if status := roomErrorStatus(err); status != http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load rooms"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rooms": page.Rooms, "nextCursor": page.NextCursor, "limit": limit, "offset": offset})
+}
+
+func GetRoom(c *gin.Context) {
+	// 非公開ルームは参加者にのみ見せるため、トークンがあれば読み取る
+	playerID := ""
+	if player, _, err := auth.AuthenticateRequest(c.Request); err == nil {
+		playerID = player.ID
+	}
+
+	room, err := Rooms.GetRoom(playerID, c.Param("id"))
+	if err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+func CreateRoom(c *gin.Context) {
+	var opts lobby.RoomOptions
+	if err := c.ShouldBindJSON(&opts); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	room, err := Rooms.CreateRoom(auth.CurrentPlayer(c).ID, opts)
+	if err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, room)
+}
+
+func JoinRoom(c *gin.Context) {
+	var opts lobby.JoinOptions
+	if err := c.ShouldBindJSON(&opts); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	room, err := Rooms.JoinRoom(auth.CurrentPlayer(c).ID, c.Param("id"), opts)
+	if err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+func DeleteRoom(c *gin.Context) {
+	if err := Rooms.DeleteRoom(auth.CurrentPlayer(c).ID, c.Param("id")); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"be-binareversi/auth"
+	"be-binareversi/db"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 現在のセッションを失効させ、新しいトークンを発行する
+func RefreshSession(c *gin.Context) {
+	player := auth.CurrentPlayer(c)
+	claims := auth.CurrentClaims(c)
+
+	token, expiresAt, err := auth.IssueToken(player.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue session"})
+		return
+	}
+	if err := db.RevokeSession(claims.SessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, RegisterResponse{UserID: player.ID, Name: player.Name, Token: token, ExpiresAt: &expiresAt})
+}
+
+// 現在のセッションを失効させる（all=true なら全セッション）
+func RevokeSession(c *gin.Context) {
+	player := auth.CurrentPlayer(c)
+	claims := auth.CurrentClaims(c)
+
+	var err error
+	if c.Query("all") == "true" {
+		err = db.RevokePlayerSessions(player.ID)
+	} else {
+		err = db.RevokeSession(claims.SessionID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke session"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
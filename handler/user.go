@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"be-binareversi/auth"
 	"be-binareversi/db"
 	"be-binareversi/model"
 	"net/http"
@@ -15,8 +16,10 @@ type RegisterRequest struct {
 }
 
 type RegisterResponse struct {
-	UserID string `json:"userID"`
-	Name   string `json:"name"`
+	UserID    string     `json:"userID"`
+	Name      string     `json:"name"`
+	Token     string     `json:"token,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 func RegisterPlayer(c *gin.Context) {
@@ -39,5 +42,11 @@ func RegisterPlayer(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, RegisterResponse{UserID: id, Name: req.Name})
+	token, expiresAt, err := auth.IssueToken(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, RegisterResponse{UserID: id, Name: req.Name, Token: token, ExpiresAt: &expiresAt})
 }
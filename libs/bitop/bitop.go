@@ -7,12 +7,14 @@ import (
 )
 
 // ApplyBitOperation は、reversi.Game の Board の特定行に対して演算を適用し、更新後の行を返します。
-// @param row [8]int オセロの1行（0と1と7）
+// @param row []int オセロの1行（0と1と7。長さは盤面の一辺。書き換えない）
 // @param value int 演算対象値（2進数で解釈）
 // @param operator string "+" または "*" を指定
-// @return [8]int 更新後の行
+// @return []int 更新後の行（新しいスライス）
 // @return error 不正な演算子や行の長さ不正時
-func ApplyBitOperation(row [8]int, value int, operator string) ([8]int, error) {
+func ApplyBitOperation(row []int, value int, operator string) ([]int, error) {
+	row = append([]int(nil), row...)
+
 	// 対象ビットインデックスを取得
 	var bitIndices []int
 	var bits []rune
@@ -0,0 +1,196 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 1メッセージあたりの最大文字数
+const MaxLength = 200
+
+var (
+	ErrEmpty       = errors.New("message is empty")
+	ErrTooLong     = errors.New("message is too long")
+	ErrRateLimited = errors.New("too many messages, slow down")
+	ErrRejected    = errors.New("message rejected by filter")
+)
+
+// Message はチャットの1発言を表す構造体
+type Message struct {
+	ID       int       `json:"id"`
+	Channel  string    `json:"channel"`
+	PlayerID string    `json:"playerID"`
+	Name     string    `json:"name"`
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sentAt"`
+}
+
+// Filter は発言内容を検査・加工するフィルタのインターフェース
+type Filter interface {
+	// 加工後の本文を返す。発言自体を拒否する場合は ErrRejected などを返す
+	Apply(text string) (string, error)
+}
+
+// 本文の前後の空白を除去し、長さを検証する
+// @param text 発言内容
+// @return string 整形後の本文
+// @return error 空または長すぎる場合はエラー
+func Normalize(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmpty
+	}
+	if utf8.RuneCountInString(text) > MaxLength {
+		return "", ErrTooLong
+	}
+	return text, nil
+}
+
+// WordListFilter は禁止語を伏せ字にする単純なフィルタ
+type WordListFilter struct {
+	words []string
+}
+
+// 禁止語リストからフィルタを作成する
+// @param words 禁止語（大文字小文字は区別しない）
+// @return *WordListFilter フィルタ
+func NewWordListFilter(words ...string) *WordListFilter {
+	f := &WordListFilter{}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f.words = append(f.words, w)
+		}
+	}
+	return f
+}
+
+// 禁止語を同じ長さの * に置き換える
+func (f *WordListFilter) Apply(text string) (string, error) {
+	lower := strings.ToLower(text)
+	out := []rune(text)
+	for _, w := range f.words {
+		wordLen := utf8.RuneCountInString(w)
+		for offset := 0; ; {
+			idx := strings.Index(lower[offset:], w)
+			if idx < 0 {
+				break
+			}
+			start := utf8.RuneCountInString(lower[:offset+idx])
+			for i := start; i < start+wordLen && i < len(out); i++ {
+				out[i] = '*'
+			}
+			offset += idx + len(w)
+		}
+	}
+	return string(out), nil
+}
+
+// Chain は複数のフィルタを順に適用する
+type Chain []Filter
+
+func (c Chain) Apply(text string) (string, error) {
+	var err error
+	for _, f := range c {
+		if text, err = f.Apply(text); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+// RateLimiter はプレイヤーごとの発言回数を一定時間内に制限する
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	sent   map[string][]time.Time
+	swept  time.Time // 最後に古い記録を掃除した時刻
+}
+
+// 発言回数の制限を作成する
+// @param limit window 内に許可する発言数
+// @param window 集計する時間幅
+// @return *RateLimiter 制限
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, sent: make(map[string][]time.Time)}
+}
+
+// 発言してよいかを判定し、許可した場合は記録する
+// @param playerID 発言者
+// @param now 現在時刻
+// @return bool 許可なら true
+func (r *RateLimiter) Allow(playerID string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.swept) >= r.window {
+		r.sweep(now)
+	}
+	recent := r.sent[playerID][:0]
+	for _, t := range r.sent[playerID] {
+		if now.Sub(t) < r.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= r.limit {
+		r.sent[playerID] = recent
+		return false
+	}
+	r.sent[playerID] = append(recent, now)
+	return true
+}
+
+// window より長く発言していないプレイヤーの記録を削除する（r.mu を保持して呼ぶ）
+// @param now 現在時刻
+func (r *RateLimiter) sweep(now time.Time) {
+	for id, sent := range r.sent {
+		if len(sent) == 0 || now.Sub(sent[len(sent)-1]) >= r.window {
+			delete(r.sent, id)
+		}
+	}
+	r.swept = now
+}
+
+// History は直近の発言を一定件数だけ保持する
+type History struct {
+	mu     sync.Mutex
+	max    int
+	nextID int
+	msgs   []Message
+}
+
+// 発言履歴を作成する
+// @param max 保持する最大件数
+// @return *History 履歴
+func NewHistory(max int) *History {
+	return &History{max: max}
+}
+
+// 発言を追加し、ID を振って返す
+// @param m 追加する発言
+// @return Message ID 付きの発言
+func (h *History) Add(m Message) Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	m.ID = h.nextID
+	h.msgs = append(h.msgs, m)
+	if len(h.msgs) > h.max {
+		h.msgs = h.msgs[len(h.msgs)-h.max:]
+	}
+	return m
+}
+
+// 保持している発言を古い順に返す
+func (h *History) List() []Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]Message, len(h.msgs))
+	copy(out, h.msgs)
+	return out
+}
@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test01_Normalize(t *testing.T) {
+	if _, err := Normalize("   "); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+	if _, err := Normalize(strings.Repeat("あ", MaxLength+1)); err != ErrTooLong {
+		t.Errorf("Expected ErrTooLong, got %v", err)
+	}
+	text, err := Normalize("  hello ")
+	if err != nil || text != "hello" {
+		t.Errorf("Expected trimmed text, got %q (%v)", text, err)
+	}
+}
+
+func Test02_WordListFilterMasks(t *testing.T) {
+	f := NewWordListFilter("bad", "ばか")
+	got, err := f.Apply("This is BAD, ばかだ, bad!")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "This is ***, **だ, ***!"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+type rejectAll struct{}
+
+func (rejectAll) Apply(string) (string, error) { return "", ErrRejected }
+
+func Test03_ChainStopsOnError(t *testing.T) {
+	c := Chain{NewWordListFilter("bad"), rejectAll{}}
+	if _, err := c.Apply("bad"); !errors.Is(err, ErrRejected) {
+		t.Errorf("Expected ErrRejected, got %v", err)
+	}
+}
+
+func Test04_RateLimiter(t *testing.T) {
+	r := NewRateLimiter(2, time.Second)
+	base := time.Now()
+	if !r.Allow("p1", base) || !r.Allow("p1", base) {
+		t.Fatal("First two messages should be allowed")
+	}
+	if r.Allow("p1", base.Add(500*time.Millisecond)) {
+		t.Error("Third message within window should be rejected")
+	}
+	if !r.Allow("p2", base) {
+		t.Error("Other players should not be limited")
+	}
+	if !r.Allow("p1", base.Add(1100*time.Millisecond)) {
+		t.Error("Message after window should be allowed")
+	}
+}
+
+func Test05_HistoryKeepsLatest(t *testing.T) {
+	h := NewHistory(2)
+	h.Add(Message{Text: "a"})
+	h.Add(Message{Text: "b"})
+	last := h.Add(Message{Text: "c"})
+	if last.ID != 3 {
+		t.Errorf("Expected ID 3, got %d", last.ID)
+	}
+	list := h.List()
+	if len(list) != 2 || list[0].Text != "b" || list[1].Text != "c" {
+		t.Errorf("Unexpected history: %+v", list)
+	}
+}
+
+func Test06_RateLimiterEvictsIdlePlayers(t *testing.T) {
+	r := NewRateLimiter(2, time.Second)
+	base := time.Now()
+	r.Allow("idle", base)
+	r.Allow("active", base.Add(900*time.Millisecond))
+
+	// window を過ぎた次の発言で、しばらく発言していないプレイヤーの記録が消える
+	r.Allow("active", base.Add(1500*time.Millisecond))
+	if _, ok := r.sent["idle"]; ok {
+		t.Error("Expected idle player to be evicted")
+	}
+	if len(r.sent["active"]) != 2 {
+		t.Errorf("Expected active player to keep recent messages, got %v", r.sent["active"])
+	}
+}
@@ -0,0 +1,162 @@
+package clock
+
+import (
+	"errors"
+	"time"
+)
+
+// 持ち時間の方式
+const (
+	ModeNone    = ""         // 時間制限なし
+	ModeFischer = "fischer"  // 持ち時間 + 1手ごとの加算
+	ModePerMove = "per_move" // 1手ごとの固定時間
+)
+
+// Control は持ち時間の設定を表す構造体
+type Control struct {
+	Mode      string        // 方式
+	Initial   time.Duration // 初期持ち時間（fischer）
+	Increment time.Duration // 1手ごとの加算時間（fischer）
+	PerMove   time.Duration // 1手あたりの制限時間（per_move）
+}
+
+// Clock は対局時計の状態を管理する構造体
+// 色のインデックスは reversi の White(0) / Black(1) に合わせる
+type Clock struct {
+	control   Control
+	remaining [2]time.Duration
+	running   int // 計測中の色（-1 なら停止中）
+	startedAt time.Time
+}
+
+// 設定値を検証する
+// @return error 不正な設定であればエラー
+func (c Control) Validate() error {
+	switch c.Mode {
+	case ModeNone:
+		return nil
+	case ModeFischer:
+		if c.Initial <= 0 || c.Increment < 0 {
+			return errors.New("invalid fischer time control")
+		}
+		return nil
+	case ModePerMove:
+		if c.PerMove <= 0 {
+			return errors.New("invalid per-move time control")
+		}
+		return nil
+	}
+	return errors.New("unknown time control mode")
+}
+
+// 新しい対局時計を返す（時間制限なしの場合は nil）
+// @param c 持ち時間の設定
+// @return *Clock 停止状態の時計
+func New(c Control) *Clock {
+	if c.Mode == ModeNone {
+		return nil
+	}
+	cl := &Clock{control: c, running: -1}
+	for i := range cl.remaining {
+		if c.Mode == ModePerMove {
+			cl.remaining[i] = c.PerMove
+		} else {
+			cl.remaining[i] = c.Initial
+		}
+	}
+	return cl
+}
+
+// 指定した色の計測を開始する
+// @param color 計測を始める色
+// @param now 現在時刻
+func (cl *Clock) Start(color int, now time.Time) {
+	cl.running = color
+	cl.startedAt = now
+}
+
+// 計測中かどうかを返す
+func (cl *Clock) Running() bool {
+	return cl.running >= 0
+}
+
+// 手番を終えた側の時計を止め、次の手番の計測を開始する
+// @param next 次に手番となる色
+// @param now 現在時刻
+// @return time.Duration 手番を終えた側が使った時間
+// @return bool 手番を終えた側が時間切れであれば true
+func (cl *Clock) Press(next int, now time.Time) (time.Duration, bool) {
+	used, flagged := cl.Stop(now)
+	if flagged {
+		return used, true
+	}
+	if cl.control.Mode == ModePerMove {
+		cl.remaining[next] = cl.control.PerMove
+	}
+	cl.Start(next, now)
+	return used, false
+}
+
+// 時計を止める
+// @param now 現在時刻
+// @return time.Duration 計測中だった側が使った時間
+// @return bool 計測中だった側が時間切れであれば true
+func (cl *Clock) Stop(now time.Time) (time.Duration, bool) {
+	if cl.running < 0 {
+		return 0, false
+	}
+	color := cl.running
+	used := now.Sub(cl.startedAt)
+	cl.running = -1
+
+	cl.remaining[color] -= used
+	if cl.remaining[color] <= 0 {
+		cl.remaining[color] = 0
+		return used, true
+	}
+	if cl.control.Mode == ModeFischer {
+		cl.remaining[color] += cl.control.Increment
+	}
+	return used, false
+}
+
+// 指定した色の残り時間を返す
+// @param color 対象の色
+// @param now 現在時刻
+// @return time.Duration 残り時間（0 未満にはならない）
+func (cl *Clock) Remaining(color int, now time.Time) time.Duration {
+	r := cl.remaining[color]
+	if cl.running == color {
+		r -= now.Sub(cl.startedAt)
+	}
+	if r < 0 {
+		return 0
+	}
+	return r
+}
+
+// State は時計の残り時間（待ったで巻き戻すために保持する）
+type State struct {
+	remaining [2]time.Duration
+}
+
+// 手番開始時点の残り時間を返す（計測中の側の経過時間は含めない）
+// @return State 残り時間
+func (cl *Clock) Snapshot() State {
+	return State{remaining: cl.remaining}
+}
+
+// 残り時間を戻し、指定した色の計測を開始する（加算や1手ごとの再設定は行わない）
+// @param s Snapshot で取得した残り時間
+// @param color 計測を始める色
+// @param now 現在時刻
+func (cl *Clock) Restore(s State, color int, now time.Time) {
+	cl.remaining = s.remaining
+	cl.Start(color, now)
+}
+
+// 計測中の色を返す
+// @return int 計測中の色（停止中なら -1）
+func (cl *Clock) Turn() int {
+	return cl.running
+}
@@ -0,0 +1,107 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	white = 0
+	black = 1
+)
+
+func Test01_NewNoneReturnsNil(t *testing.T) {
+	if New(Control{}) != nil {
+		t.Error("Expected nil clock for ModeNone")
+	}
+}
+
+func Test02_FischerIncrement(t *testing.T) {
+	base := time.Now()
+	cl := New(Control{Mode: ModeFischer, Initial: 60 * time.Second, Increment: 5 * time.Second})
+	cl.Start(black, base)
+
+	used, flagged := cl.Press(white, base.Add(10*time.Second))
+	if flagged {
+		t.Fatal("Did not expect flag")
+	}
+	if used != 10*time.Second {
+		t.Errorf("Expected 10s used, got %v", used)
+	}
+	if got := cl.Remaining(black, base.Add(10*time.Second)); got != 55*time.Second {
+		t.Errorf("Expected 55s remaining for Black, got %v", got)
+	}
+	if got := cl.Remaining(white, base.Add(13*time.Second)); got != 57*time.Second {
+		t.Errorf("Expected 57s remaining for White, got %v", got)
+	}
+}
+
+func Test03_PerMoveResets(t *testing.T) {
+	base := time.Now()
+	cl := New(Control{Mode: ModePerMove, PerMove: 30 * time.Second})
+	cl.Start(black, base)
+	cl.Press(white, base.Add(20*time.Second))
+	cl.Press(black, base.Add(25*time.Second))
+
+	if got := cl.Remaining(black, base.Add(25*time.Second)); got != 30*time.Second {
+		t.Errorf("Expected per-move time to reset to 30s, got %v", got)
+	}
+}
+
+func Test04_Flag(t *testing.T) {
+	base := time.Now()
+	cl := New(Control{Mode: ModeFischer, Initial: 5 * time.Second})
+	cl.Start(black, base)
+
+	_, flagged := cl.Press(white, base.Add(6*time.Second))
+	if !flagged {
+		t.Error("Expected Black to flag")
+	}
+	if cl.Running() {
+		t.Error("Clock should be stopped after flag")
+	}
+	if got := cl.Remaining(black, base.Add(6*time.Second)); got != 0 {
+		t.Errorf("Expected 0 remaining, got %v", got)
+	}
+}
+
+func Test05_Validate(t *testing.T) {
+	cases := []struct {
+		c     Control
+		valid bool
+	}{
+		{Control{}, true},
+		{Control{Mode: ModeFischer, Initial: time.Minute}, true},
+		{Control{Mode: ModeFischer}, false},
+		{Control{Mode: ModePerMove, PerMove: time.Second}, true},
+		{Control{Mode: ModePerMove}, false},
+		{Control{Mode: "blitz"}, false},
+	}
+	for i, tc := range cases {
+		if err := tc.c.Validate(); (err == nil) != tc.valid {
+			t.Errorf("case %d: expected valid=%v, got err=%v", i, tc.valid, err)
+		}
+	}
+}
+
+func Test06_SnapshotRestore(t *testing.T) {
+	base := time.Now()
+	cl := New(Control{Mode: ModeFischer, Initial: 60 * time.Second, Increment: 5 * time.Second})
+	cl.Start(black, base)
+	cl.Press(white, base.Add(10*time.Second))
+
+	// 白の手番開始時点を保存し、白が打った後で巻き戻す
+	snap := cl.Snapshot()
+	cl.Press(black, base.Add(30*time.Second))
+	cl.Restore(snap, white, base.Add(40*time.Second))
+
+	if got := cl.Remaining(white, base.Add(40*time.Second)); got != 60*time.Second {
+		t.Errorf("Expected White's time without increment to be restored, got %v", got)
+	}
+	if got := cl.Remaining(black, base.Add(40*time.Second)); got != 55*time.Second {
+		t.Errorf("Expected Black's time to be restored, got %v", got)
+	}
+	if cl.Turn() != white {
+		t.Errorf("Expected White's clock to be running, got %d", cl.Turn())
+	}
+}
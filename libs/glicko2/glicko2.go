@@ -0,0 +1,118 @@
+package glicko2
+
+import "math"
+
+// 初期値とシステム定数
+const (
+	DefaultRating     = 1500.0
+	DefaultRD         = 350.0
+	DefaultVolatility = 0.06
+
+	// RD がこの値より大きい間は暫定レーティングとして扱う
+	ProvisionalRD = 110.0
+
+	scale   = 173.7178 // Glicko と Glicko-2 の尺度変換
+	tau     = 0.5      // ボラティリティの変化を抑える定数
+	epsilon = 0.000001 // 収束判定
+)
+
+// Rating はプレイヤーのレーティング
+type Rating struct {
+	Rating     float64
+	RD         float64
+	Volatility float64
+}
+
+// Result は1局の結果（Score は勝ち=1, 引き分け=0.5, 負け=0）
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+// 初期状態のレーティングを返す
+func NewRating() Rating {
+	return Rating{Rating: DefaultRating, RD: DefaultRD, Volatility: DefaultVolatility}
+}
+
+// 暫定レーティングかどうかを返す
+func (r Rating) Provisional() bool {
+	return r.RD > ProvisionalRD
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, phiJ float64) float64 {
+	return 1 / (1 + math.Exp(-g(phiJ)*(mu-muJ)))
+}
+
+// 1評価期間分の結果からレーティングを更新する
+// @param r 更新前のレーティング
+// @param results 期間中の対局結果（空なら RD だけが増える）
+// @return Rating 更新後のレーティング
+func Update(r Rating, results []Result) Rating {
+	mu := (r.Rating - DefaultRating) / scale
+	phi := r.RD / scale
+	sigma := r.Volatility
+
+	if len(results) == 0 {
+		phiStar := math.Min(math.Sqrt(phi*phi+sigma*sigma), DefaultRD/scale)
+		return Rating{Rating: r.Rating, RD: phiStar * scale, Volatility: sigma}
+	}
+
+	// 推定分散 v と改善量 delta
+	var vInv, deltaSum float64
+	for _, res := range results {
+		muJ := (res.Opponent.Rating - DefaultRating) / scale
+		phiJ := res.Opponent.RD / scale
+		e := expected(mu, muJ, phiJ)
+		gj := g(phiJ)
+		vInv += gj * gj * e * (1 - e)
+		deltaSum += gj * (res.Score - e)
+	}
+	v := 1 / vInv
+	delta := v * deltaSum
+
+	// 新しいボラティリティ（Illinois 法）
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		num := ex * (delta*delta - phi*phi - v - ex)
+		den := 2 * math.Pow(phi*phi+v+ex, 2)
+		return num/den - (x-a)/(tau*tau)
+	}
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	newSigma := math.Exp(A / 2)
+
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*deltaSum
+
+	return Rating{
+		Rating:     newMu*scale + DefaultRating,
+		RD:         math.Min(newPhi*scale, DefaultRD),
+		Volatility: newSigma,
+	}
+}
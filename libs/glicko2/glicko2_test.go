@@ -0,0 +1,63 @@
+package glicko2
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol
+}
+
+// Glickman の論文の計算例
+func Test01_UpdateMatchesPaperExample(t *testing.T) {
+	r := Rating{Rating: 1500, RD: 200, Volatility: 0.06}
+	results := []Result{
+		{Opponent: Rating{Rating: 1400, RD: 30}, Score: 1},
+		{Opponent: Rating{Rating: 1550, RD: 100}, Score: 0},
+		{Opponent: Rating{Rating: 1700, RD: 300}, Score: 0},
+	}
+
+	got := Update(r, results)
+	if !near(got.Rating, 1464.06, 0.01) {
+		t.Errorf("Expected rating 1464.06, got %.2f", got.Rating)
+	}
+	if !near(got.RD, 151.52, 0.01) {
+		t.Errorf("Expected RD 151.52, got %.2f", got.RD)
+	}
+	if !near(got.Volatility, 0.05999, 0.00001) {
+		t.Errorf("Expected volatility 0.05999, got %.5f", got.Volatility)
+	}
+}
+
+func Test02_NoGamesIncreasesRD(t *testing.T) {
+	r := Rating{Rating: 1500, RD: 50, Volatility: 0.06}
+	got := Update(r, nil)
+	if got.Rating != 1500 {
+		t.Errorf("Rating should not change, got %.2f", got.Rating)
+	}
+	if got.RD <= 50 {
+		t.Errorf("Expected RD to increase, got %.2f", got.RD)
+	}
+}
+
+func Test03_WinnerGainsLoserLoses(t *testing.T) {
+	a, b := NewRating(), NewRating()
+	newA := Update(a, []Result{{Opponent: b, Score: 1}})
+	newB := Update(b, []Result{{Opponent: a, Score: 0}})
+	if newA.Rating <= a.Rating || newB.Rating >= b.Rating {
+		t.Errorf("Unexpected ratings: winner %.2f, loser %.2f", newA.Rating, newB.Rating)
+	}
+	if !near(newA.Rating-1500, 1500-newB.Rating, 0.001) {
+		t.Errorf("Expected symmetric change, got %.2f / %.2f", newA.Rating, newB.Rating)
+	}
+}
+
+func Test04_Provisional(t *testing.T) {
+	if !NewRating().Provisional() {
+		t.Error("New rating should be provisional")
+	}
+	if (Rating{Rating: 1500, RD: 80, Volatility: 0.06}).Provisional() {
+		t.Error("RD 80 should not be provisional")
+	}
+}
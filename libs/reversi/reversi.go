@@ -11,6 +11,15 @@ const (
 	Empty = 7 // 空きマス
 )
 
+// 盤面の一辺のマス数（偶数のみ）
+const (
+	DefaultSize = 8
+	MinSize     = 4
+	MaxSize     = 16
+)
+
+var ErrInvalidSize = errors.New("board size must be an even number from 4 to 16")
+
 // Point は座標 (x, y) を表す構造体
 type Point struct {
 	X int
@@ -19,10 +28,11 @@ type Point struct {
 
 // Game はオセロゲームの状態を管理する構造体
 type Game struct {
-	RoomID    string    // ゲームのルーム識別子
-	Board     [8][8]int // 盤面の状態
-	Turn      int       // 現在の手番（1=Black, 0=White）
-	TurnCount int       // 手番のカウント
+	RoomID    string  // ゲームのルーム識別子
+	Board     [][]int // 盤面の状態（Size x Size）
+	Size      int     // 一辺のマス数
+	Turn      int     // 現在の手番（1=Black, 0=White）
+	TurnCount int     // 手番のカウント
 }
 
 var directions = []Point{
@@ -31,34 +41,66 @@ var directions = []Point{
 	{1, -1}, {1, 0}, {1, 1},
 }
 
-// 新しいオセロゲームを初期化して返す
+// 盤面の一辺として使えるかを判定する
+// @param size 一辺のマス数
+// @return error 使えなければ ErrInvalidSize
+func ValidSize(size int) error {
+	if size < MinSize || size > MaxSize || size%2 != 0 {
+		return ErrInvalidSize
+	}
+	return nil
+}
+
+// 空の盤面を作成する
+// @param size 一辺のマス数
+// @return [][]int すべて空きマスの盤面
+func NewBoard(size int) [][]int {
+	board := make([][]int, size)
+	for i := range board {
+		board[i] = make([]int, size)
+		for j := range board[i] {
+			board[i][j] = Empty
+		}
+	}
+	return board
+}
+
+// 盤面を複製する
+// @param board 元の盤面
+// @return [][]int 行ごとに複製した盤面
+func CopyBoard(board [][]int) [][]int {
+	copied := make([][]int, len(board))
+	for i, row := range board {
+		copied[i] = append([]int(nil), row...)
+	}
+	return copied
+}
+
+// 新しいオセロゲーム（8x8）を初期化して返す
 // @param roomID ゲームを識別するためのID
 // @return 初期化済みの *Game インスタンス
 func NewGame(roomID string) *Game {
-	g := &Game{
-		RoomID:    roomID,
-		Turn:      Black,
-		TurnCount: 1,
-	}
-	g.initBoard()
-	return g
+	return NewGameFrom(roomID, StandardSetup(DefaultSize))
 }
 
-// 盤面を初期状態に設定する
-func (g *Game) initBoard() {
-	for i := range g.Board {
-		for j := range g.Board[i] {
-			g.Board[i][j] = Empty
-		}
+// 指定した開始局面からオセロゲームを初期化して返す
+// @param roomID ゲームを識別するためのID
+// @param setup 開始局面（Validate 済みであること）
+// @return 初期化済みの *Game インスタンス
+func NewGameFrom(roomID string, setup Setup) *Game {
+	return &Game{
+		RoomID:    roomID,
+		Board:     CopyBoard(setup.Board),
+		Size:      len(setup.Board),
+		Turn:      setup.Turn,
+		TurnCount: 1,
 	}
-	g.Board[3][3], g.Board[4][4] = White, White
-	g.Board[3][4], g.Board[4][3] = Black, Black
 }
 
 // 現在の盤面を返す
-// @return [8][8]int 現在の盤面
-func (g *Game) GetBoard() [8][8]int {
-	return g.Board
+// @return [][]int 現在の盤面（複製）
+func (g *Game) GetBoard() [][]int {
+	return CopyBoard(g.Board)
 }
 
 // 現在の手番プレイヤーを返す
@@ -89,8 +131,8 @@ func (g *Game) IncrementTurnCount() {
 // @return []Point 合法手の座標リスト
 func (g *Game) GetValidMoves(player int) []Point {
 	var moves []Point
-	for x := 0; x < 8; x++ {
-		for y := 0; y < 8; y++ {
+	for x := 0; x < g.Size; x++ {
+		for y := 0; y < g.Size; y++ {
 			if g.Board[x][y] == Empty && g.canPlace(player, x, y) {
 				moves = append(moves, Point{x, y})
 			}
@@ -100,37 +142,43 @@ func (g *Game) GetValidMoves(player int) []Point {
 }
 
 // 盤面を外部から上書きする
-// @param newBoard 新しい盤面の状態
-func (g *Game) SetBoard(newBoard [8][8]int) {
-	g.Board = newBoard
+// @param newBoard 新しい盤面の状態（一辺の長さは変えない）
+func (g *Game) SetBoard(newBoard [][]int) {
+	g.Board = CopyBoard(newBoard)
 }
 
 // 指定座標に石を置き、盤面を更新する
 // @param player 手番プレイヤー（Black=1, White=0）
 // @param x X座標
 // @param y Y座標
-// @return [8][8]int 更新後の盤面
+// @return [][]int 更新後の盤面（複製）
 // @return error 不正な手であればエラー
-func (g *Game) PlaceDisc(player, x, y int) ([8][8]int, error) {
-	if x < 0 || x >= 8 || y < 0 || y >= 8 {
-		return g.Board, errors.New("move out of board bounds")
+func (g *Game) PlaceDisc(player, x, y int) ([][]int, error) {
+	if !g.inBounds(x, y) {
+		return g.GetBoard(), errors.New("move out of board bounds")
 	}
 	if player != g.Turn {
-		return g.Board, errors.New("not your turn")
+		return g.GetBoard(), errors.New("not your turn")
 	}
 	if !g.canPlace(player, x, y) {
-		return g.Board, errors.New("invalid move")
+		return g.GetBoard(), errors.New("invalid move")
 	}
 
 	g.Board[x][y] = player
 	g.flipDiscs(player, x, y)
 	g.PassTurn()
-	return g.Board, nil
+	return g.GetBoard(), nil
 }
 
-// 現在の勝者を返す（ゲーム終了前でも呼び出し可能）
-// @return int 勝者（Black=1, White=0, 引き分け=-1）
-func (g *Game) GetWinner() int {
+// 座標が盤面の内側かを判定
+func (g *Game) inBounds(x, y int) bool {
+	return x >= 0 && x < g.Size && y >= 0 && y < g.Size
+}
+
+// 盤面上の石の数を数える
+// @return int 黒石の数
+// @return int 白石の数
+func (g *Game) CountDiscs() (int, int) {
 	blackCount, whiteCount := 0, 0
 	for _, row := range g.Board {
 		for _, cell := range row {
@@ -141,6 +189,13 @@ func (g *Game) GetWinner() int {
 			}
 		}
 	}
+	return blackCount, whiteCount
+}
+
+// 現在の勝者を返す（ゲーム終了前でも呼び出し可能）
+// @return int 勝者（Black=1, White=0, 引き分け=-1）
+func (g *Game) GetWinner() int {
+	blackCount, whiteCount := g.CountDiscs()
 
 	if blackCount > whiteCount {
 		return Black
@@ -158,9 +213,9 @@ func (g *Game) IsGameOver() bool {
 
 // 盤面を標準出力に表示（デバッグ用）
 func (g *Game) PrintBoard() {
-	for i := 0; i < 8; i++ {
+	for i := 0; i < g.Size; i++ {
 		fmt.Print("|")
-		for j := 0; j < 8; j++ {
+		for j := 0; j < g.Size; j++ {
 			fmt.Printf(" %d", g.Board[i][j])
 		}
 		fmt.Println(" |")
@@ -196,7 +251,7 @@ func (g *Game) countFlippable(player, x, y int, dir Point) int {
 	count := 0
 	nx, ny := x+dir.X, y+dir.Y
 
-	for nx >= 0 && nx < 8 && ny >= 0 && ny < 8 {
+	for g.inBounds(nx, ny) {
 		if g.Board[nx][ny] == opponent {
 			count++
 		} else if g.Board[nx][ny] == player {
@@ -229,9 +284,12 @@ func (g *Game) flipDiscs(player, x, y int) {
 
 // 合法手マップを取得（9で合法手を示す）
 // @param player プレイヤーの色
-// @return [8][8]int 合法手マップ（合法手=9, その他=0）
-func (g *Game) GetValidMovesMap(player int) [8][8]int {
-	var movesMap [8][8]int
+// @return [][]int 合法手マップ（合法手=9, その他=0）
+func (g *Game) GetValidMovesMap(player int) [][]int {
+	movesMap := make([][]int, g.Size)
+	for i := range movesMap {
+		movesMap[i] = make([]int, g.Size)
+	}
 	for _, move := range g.GetValidMoves(player) {
 		movesMap[move.X][move.Y] = 9
 	}
@@ -240,10 +298,10 @@ func (g *Game) GetValidMovesMap(player int) [8][8]int {
 
 // 盤面と合法手を同時に表示（デバッグ用）
 // @param movesMap 合法手マップ（GetValidMovesMapの結果）
-func (g *Game) PrintBoardWithMovesMap(movesMap [8][8]int) {
-	for i := 0; i < 8; i++ {
+func (g *Game) PrintBoardWithMovesMap(movesMap [][]int) {
+	for i := 0; i < g.Size; i++ {
 		fmt.Print("|")
-		for j := 0; j < 8; j++ {
+		for j := 0; j < g.Size; j++ {
 			if movesMap[i][j] == 9 {
 				// 合法手
 				fmt.Printf(" 9")
@@ -259,12 +317,12 @@ func (g *Game) PrintBoardWithMovesMap(movesMap [8][8]int) {
 
 // 合法手込みの盤面を返す（合法手=9、それ以外は通常の盤面）
 // @param player プレイヤーの色
-// @return [8][8]int 合法手を含む盤面
-func (g *Game) GetBoardWithValidMoves(player int) [8][8]int {
-	board := g.Board // コピーされる
+// @return [][]int 合法手を含む盤面
+func (g *Game) GetBoardWithValidMoves(player int) [][]int {
+	board := g.GetBoard()
 	movesMap := g.GetValidMovesMap(player)
-	for x := 0; x < 8; x++ {
-		for y := 0; y < 8; y++ {
+	for x := 0; x < g.Size; x++ {
+		for y := 0; y < g.Size; y++ {
 			if movesMap[x][y] == 9 && board[x][y] == Empty {
 				board[x][y] = 9
 			}
@@ -272,3 +330,47 @@ func (g *Game) GetBoardWithValidMoves(player int) [8][8]int {
 	}
 	return board
 }
+
+// 位置ごとの評価値（角を高く、角の隣を低く評価する）
+// 縦・横それぞれの端からの距離（3以上は3とみなす）で引く。8x8 では従来の評価表と同じになる
+var edgeDistanceWeights = [4][4]int{
+	{100, -20, 10, 5},
+	{-20, -50, -2, -2},
+	{10, -2, 1, 1},
+	{5, -2, 1, 0},
+}
+
+// 指定座標の評価値
+func (g *Game) positionWeight(x, y int) int {
+	distance := func(v int) int {
+		d := v
+		if far := g.Size - 1 - v; far < d {
+			d = far
+		}
+		if d > 3 {
+			d = 3
+		}
+		return d
+	}
+	return edgeDistanceWeights[distance(x)][distance(y)]
+}
+
+// AI用に最も評価の高い合法手を返す（位置の評価値 + 裏返せる石の数）
+// @param player プレイヤーの色
+// @return Point 選んだ手
+// @return bool 合法手がなければ false
+func (g *Game) BestMove(player int) (Point, bool) {
+	var best Point
+	bestScore := 0
+	found := false
+	for _, move := range g.GetValidMoves(player) {
+		score := g.positionWeight(move.X, move.Y)
+		for _, dir := range directions {
+			score += g.countFlippable(player, move.X, move.Y, dir)
+		}
+		if !found || score > bestScore {
+			best, bestScore, found = move, score, true
+		}
+	}
+	return best, found
+}
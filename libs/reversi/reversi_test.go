@@ -1,6 +1,9 @@
 package reversi
 
 import (
+	"errors"
+	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -164,3 +167,149 @@ func Test13_GetValidMovesMap(t *testing.T) {
 	t.Log("Board with valid moves (9):")
 	game.PrintBoardWithMovesMap(movesMap)
 }
+
+func Test14_BestMovePrefersCorner(t *testing.T) {
+	game := NewGame("room14")
+	for i := range game.Board {
+		for j := range game.Board[i] {
+			game.Board[i][j] = Empty
+		}
+	}
+	// (0,0) の角と (2,5) のどちらにも置ける局面
+	game.Board[1][1] = White
+	game.Board[2][2] = Black
+	game.Board[3][5] = White
+	game.Board[4][5] = Black
+
+	move, ok := game.BestMove(Black)
+	if !ok {
+		t.Fatal("Expected a valid move")
+	}
+	if move != (Point{0, 0}) {
+		t.Errorf("Expected corner (0,0), got %v", move)
+	}
+}
+
+func Test15_BestMoveNoMoves(t *testing.T) {
+	game := NewGame("room15")
+	for i := range game.Board {
+		for j := range game.Board[i] {
+			game.Board[i][j] = Black
+		}
+	}
+	if _, ok := game.BestMove(White); ok {
+		t.Error("Expected no valid move on a full board")
+	}
+}
+
+func Test16_CountDiscs(t *testing.T) {
+	game := NewGame("room16")
+	if _, err := game.PlaceDisc(Black, 2, 3); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	black, white := game.CountDiscs()
+	if black != 4 || white != 1 {
+		t.Errorf("Expected 4 black and 1 white, got %d and %d", black, white)
+	}
+}
+
+func Test17_ParseSetup(t *testing.T) {
+	standard := StandardSetup(DefaultSize)
+	parsed, err := ParseSetup(standard.String())
+	if err != nil || !parsed.Equal(standard) {
+		t.Fatalf("Expected standard setup to round-trip, got %v", err)
+	}
+
+	// 空白は無視し、手番を指定できる
+	position := strings.Repeat("--------\n", 3) + "---OX---\n---XO---\n" + strings.Repeat("--------\n", 3) + "O"
+	parsed, err = ParseSetup(position)
+	if err != nil || parsed.Turn != White || parsed.Board[3][3] != White {
+		t.Errorf("Expected white to move from standard center, got %+v, %v", parsed, err)
+	}
+
+	for _, bad := range []string{
+		"",                           // 短すぎる
+		strings.Repeat("-", 64),      // 中央が空き
+		strings.Repeat("X", 64),      // 合法手がない
+		strings.Repeat("?", 64),      // 不明な文字
+		standard.String()[:64] + "-", // 手番が空き
+	} {
+		if _, err := ParseSetup(bad); !errors.Is(err, ErrInvalidSetup) {
+			t.Errorf("Expected ErrInvalidSetup for %q, got %v", bad, err)
+		}
+	}
+}
+
+func Test18_HandicapSetup(t *testing.T) {
+	setup, err := HandicapSetup(DefaultSize, White, 2)
+	if err != nil {
+		t.Fatalf("HandicapSetup failed: %v", err)
+	}
+	if setup.Board[0][0] != White || setup.Board[7][7] != White || setup.Board[0][7] != Empty {
+		t.Errorf("Expected two white corners, got %v", setup.Board)
+	}
+	game := NewGameFrom("room18", setup)
+	if game.Turn != Black || len(game.GetValidMoves(Black)) == 0 {
+		t.Error("Expected black to move first with legal moves")
+	}
+
+	if _, err := HandicapSetup(DefaultSize, Black, 5); !errors.Is(err, ErrInvalidHandicap) {
+		t.Errorf("Expected ErrInvalidHandicap, got %v", err)
+	}
+	if _, err := HandicapSetup(DefaultSize, Empty, 1); !errors.Is(err, ErrInvalidSetup) {
+		t.Errorf("Expected ErrInvalidSetup for unknown color, got %v", err)
+	}
+}
+
+func Test19_RandomSetupIsBalanced(t *testing.T) {
+	rng := rand.New(rand.NewSource(19))
+	for i := 0; i < 20; i++ {
+		setup := RandomSetup(DefaultSize, rng.Intn)
+		if err := setup.Validate(); err != nil {
+			t.Fatalf("Expected a playable setup, got %v", err)
+		}
+		game := NewGameFrom("room19", setup)
+		black, white := game.CountDiscs()
+		if black+white != 4+randomOpeningPlies {
+			t.Errorf("Expected %d discs, got %d", 4+randomOpeningPlies, black+white)
+		}
+		if black-white > randomOpeningMaxDiff || white-black > randomOpeningMaxDiff {
+			t.Errorf("Expected a balanced setup, got %d-%d", black, white)
+		}
+	}
+}
+
+func Test20_BoardSizes(t *testing.T) {
+	for _, size := range []int{6, 10, 12} {
+		game := NewGameFrom("room20", StandardSetup(size))
+		c := size / 2
+		if game.Size != size || len(game.Board) != size || game.Board[c-1][c-1] != White || game.Board[c][c-1] != Black {
+			t.Fatalf("Expected standard %dx%d start, got %v", size, size, game.Board)
+		}
+		if moves := game.GetValidMoves(Black); len(moves) != 4 {
+			t.Errorf("Expected 4 opening moves on %dx%d, got %d", size, size, len(moves))
+		}
+		if _, err := game.PlaceDisc(Black, size-1, size-1); err == nil {
+			t.Errorf("Expected corner to be invalid at the start on %dx%d", size, size)
+		}
+		if _, err := game.PlaceDisc(Black, size, 0); err == nil {
+			t.Errorf("Expected out of bounds on %dx%d", size, size)
+		}
+
+		// 局面文字列の文字数から大きさを読み取る
+		parsed, err := ParseSetup(StandardSetup(size).String())
+		if err != nil || len(parsed.Board) != size {
+			t.Errorf("Expected %dx%d setup to round-trip, got %v", size, size, err)
+		}
+		handicap, err := HandicapSetup(size, White, 4)
+		if err != nil || handicap.Board[size-1][0] != White {
+			t.Errorf("Expected handicap corners on %dx%d, got %v", size, size, err)
+		}
+	}
+
+	for _, size := range []int{2, 7, 18} {
+		if err := ValidSize(size); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("Expected ErrInvalidSize for %d, got %v", size, err)
+		}
+	}
+}
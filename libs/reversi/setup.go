@@ -0,0 +1,212 @@
+package reversi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 局面文字列で使う文字（石の色ごと）
+const (
+	blackChar = 'X'
+	whiteChar = 'O'
+	emptyChar = '-'
+)
+
+// ランダムな開始局面を作るときに打つ手数と、互角とみなす石数の差
+const (
+	randomOpeningPlies    = 6
+	randomOpeningMaxDiff  = 2
+	randomOpeningAttempts = 100
+)
+
+// ハンデの角の数の上限
+const maxHandicap = 4
+
+var (
+	ErrInvalidSetup    = errors.New("invalid starting position")
+	ErrInvalidHandicap = errors.New("handicap must be 1 to 4 corners")
+)
+
+// Setup は対局の開始局面
+type Setup struct {
+	Board [][]int // 盤面（一辺の長さが盤面の大きさ）
+	Turn  int     // 最初の手番（1=Black, 0=White）
+}
+
+// 標準の開始局面（中央に4石、黒番）を返す
+// @param size 一辺のマス数（ValidSize を満たすこと）
+// @return Setup 標準の開始局面
+func StandardSetup(size int) Setup {
+	s := Setup{Board: NewBoard(size), Turn: Black}
+	c := size / 2
+	s.Board[c-1][c-1], s.Board[c][c] = White, White
+	s.Board[c-1][c], s.Board[c][c-1] = Black, Black
+	return s
+}
+
+// 標準の開始局面の角に、ハンデを受ける側の石を置いた局面を返す
+// @param size 一辺のマス数
+// @param color ハンデを受ける色
+// @param corners 置く角の数（1〜4。左上, 右下, 右上, 左下の順に置く）
+// @return Setup ハンデ付きの開始局面（黒番）
+// @return error 大きさ・角の数・色が不正であればエラー
+func HandicapSetup(size, color, corners int) (Setup, error) {
+	if err := ValidSize(size); err != nil {
+		return Setup{}, err
+	}
+	if color != Black && color != White {
+		return Setup{}, fmt.Errorf("%w: unknown color %d", ErrInvalidSetup, color)
+	}
+	if corners < 1 || corners > maxHandicap {
+		return Setup{}, ErrInvalidHandicap
+	}
+	last := size - 1
+	s := StandardSetup(size)
+	for _, p := range []Point{{0, 0}, {last, last}, {0, last}, {last, 0}}[:corners] {
+		s.Board[p.X][p.Y] = color
+	}
+	return s, s.Validate()
+}
+
+// 標準の開始局面からランダムに数手進めた、石数が互角の局面を返す
+// @param size 一辺のマス数（ValidSize を満たすこと）
+// @param intn 0 以上 n 未満の乱数を返す関数（rand.Intn など）
+// @return Setup 開始局面（互角の局面が見つからなければ標準の開始局面）
+func RandomSetup(size int, intn func(n int) int) Setup {
+	for attempt := 0; attempt < randomOpeningAttempts; attempt++ {
+		g := NewGameFrom("", StandardSetup(size))
+		for ply := 0; ply < randomOpeningPlies; ply++ {
+			moves := g.GetValidMoves(g.Turn)
+			if len(moves) == 0 {
+				break
+			}
+			move := moves[intn(len(moves))]
+			g.PlaceDisc(g.Turn, move.X, move.Y)
+		}
+		black, white := g.CountDiscs()
+		if black-white > randomOpeningMaxDiff || white-black > randomOpeningMaxDiff {
+			continue
+		}
+		if s := (Setup{Board: g.Board, Turn: g.Turn}); s.Validate() == nil {
+			return s
+		}
+	}
+	return StandardSetup(size)
+}
+
+// 局面文字列を読み取る
+// size*size 文字の盤面（X=黒, O=白, -=空き。行ごと）と、省略可能な手番（X か O。既定は黒番）。空白は無視する
+// 盤面の大きさは文字数から決める（64文字なら 8x8）
+// @param position 局面文字列
+// @return Setup 読み取った開始局面
+// @return error 形式が不正、または対局を始められない局面であればエラー
+func ParseSetup(position string) (Setup, error) {
+	cells := strings.Join(strings.Fields(strings.ToUpper(position)), "")
+	size := 0
+	for n := MinSize; n <= MaxSize; n += 2 {
+		if len(cells) == n*n || len(cells) == n*n+1 {
+			size = n
+			break
+		}
+	}
+	if size == 0 {
+		return Setup{}, fmt.Errorf("%w: expected size*size squares and an optional side to move, got %d characters", ErrInvalidSetup, len(cells))
+	}
+
+	s := Setup{Board: NewBoard(size), Turn: Black}
+	squares := size * size
+	for i := 0; i < squares; i++ {
+		color, ok := colorOf(cells[i])
+		if !ok {
+			return Setup{}, fmt.Errorf("%w: unexpected %q at square %d", ErrInvalidSetup, cells[i], i)
+		}
+		s.Board[i/size][i%size] = color
+	}
+	if len(cells) > squares {
+		color, ok := colorOf(cells[squares])
+		if !ok || color == Empty {
+			return Setup{}, fmt.Errorf("%w: side to move must be %c or %c", ErrInvalidSetup, blackChar, whiteChar)
+		}
+		s.Turn = color
+	}
+	return s, s.Validate()
+}
+
+// 局面文字列の1文字を石の色に変換する
+func colorOf(c byte) (int, bool) {
+	switch c {
+	case blackChar:
+		return Black, true
+	case whiteChar:
+		return White, true
+	case emptyChar, '.':
+		return Empty, true
+	}
+	return 0, false
+}
+
+// 局面文字列に変換する（ParseSetup で読み戻せる形式）
+// @return string size*size 文字の盤面と手番
+func (s Setup) String() string {
+	var b strings.Builder
+	for _, row := range s.Board {
+		for _, cell := range row {
+			switch cell {
+			case Black:
+				b.WriteByte(blackChar)
+			case White:
+				b.WriteByte(whiteChar)
+			default:
+				b.WriteByte(emptyChar)
+			}
+		}
+	}
+	if s.Turn == White {
+		b.WriteByte(whiteChar)
+	} else {
+		b.WriteByte(blackChar)
+	}
+	return b.String()
+}
+
+// 同じ局面かを判定する
+// @param other 比べる局面
+// @return bool 盤面と手番が同じなら true
+func (s Setup) Equal(other Setup) bool {
+	return s.String() == other.String()
+}
+
+// 対局を始められる局面かを確かめる
+// 正方形で、中央の4マスが埋まっていて（実戦で現れうる局面の条件）、手番側に合法手があること
+// @return error 始められなければ ErrInvalidSetup を含むエラー
+func (s Setup) Validate() error {
+	size := len(s.Board)
+	if err := ValidSize(size); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSetup, err)
+	}
+	if s.Turn != Black && s.Turn != White {
+		return fmt.Errorf("%w: unknown side to move %d", ErrInvalidSetup, s.Turn)
+	}
+	for _, row := range s.Board {
+		if len(row) != size {
+			return fmt.Errorf("%w: board must be square", ErrInvalidSetup)
+		}
+		for _, cell := range row {
+			if cell != Black && cell != White && cell != Empty {
+				return fmt.Errorf("%w: unknown square %d", ErrInvalidSetup, cell)
+			}
+		}
+	}
+	c := size / 2
+	for _, p := range []Point{{c - 1, c - 1}, {c - 1, c}, {c, c - 1}, {c, c}} {
+		if s.Board[p.X][p.Y] == Empty {
+			return fmt.Errorf("%w: center squares must be occupied", ErrInvalidSetup)
+		}
+	}
+	g := &Game{Board: s.Board, Size: size, Turn: s.Turn}
+	if len(g.GetValidMoves(s.Turn)) == 0 {
+		return fmt.Errorf("%w: side to move has no legal move", ErrInvalidSetup)
+	}
+	return nil
+}
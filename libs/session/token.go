@@ -0,0 +1,78 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// JWT (HS256) のヘッダ
+const header = `{"alg":"HS256","typ":"JWT"}`
+
+// Claims はトークンに含める情報
+type Claims struct {
+	PlayerID  string `json:"sub"`
+	SessionID string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var encoding = base64.RawURLEncoding
+
+func signature(secret []byte, signingInput string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return encoding.EncodeToString(mac.Sum(nil))
+}
+
+// クレームに署名してトークン文字列を返す
+// @param secret 署名鍵
+// @param claims トークンに含める情報
+// @return string トークン
+func Sign(secret []byte, claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encoding.EncodeToString([]byte(header)) + "." + encoding.EncodeToString(payload)
+	return signingInput + "." + signature(secret, signingInput), nil
+}
+
+// トークンの署名と有効期限を検証し、クレームを返す
+// @param secret 署名鍵
+// @param token トークン文字列
+// @param now 現在時刻
+// @return Claims トークンに含まれる情報
+// @return error 署名不正・期限切れの場合はエラー
+func Parse(secret []byte, token string, now time.Time) (Claims, error) {
+	var claims Claims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, ErrInvalidToken
+	}
+	expected := signature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return claims, ErrInvalidToken
+	}
+
+	payload, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.PlayerID == "" {
+		return claims, ErrInvalidToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return claims, ErrExpiredToken
+	}
+	return claims, nil
+}
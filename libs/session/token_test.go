@@ -0,0 +1,61 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("test-secret")
+
+func newClaims(now time.Time) Claims {
+	return Claims{
+		PlayerID:  "player1",
+		SessionID: "session1",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}
+}
+
+func Test01_SignAndParse(t *testing.T) {
+	now := time.Now()
+	token, err := Sign(secret, newClaims(now))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	claims, err := Parse(secret, token, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if claims.PlayerID != "player1" || claims.SessionID != "session1" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+}
+
+func Test02_TamperedToken(t *testing.T) {
+	now := time.Now()
+	token, _ := Sign(secret, newClaims(now))
+	other, _ := Sign(secret, Claims{PlayerID: "player2", ExpiresAt: now.Add(time.Hour).Unix()})
+
+	// 署名だけ別トークンのものに差し替える
+	parts := strings.Split(token, ".")
+	otherParts := strings.Split(other, ".")
+	forged := otherParts[0] + "." + otherParts[1] + "." + parts[2]
+	if _, err := Parse(secret, forged, now); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+	if _, err := Parse([]byte("wrong"), token, now); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for wrong secret, got %v", err)
+	}
+	if _, err := Parse(secret, "garbage", now); err != ErrInvalidToken {
+		t.Errorf("Expected ErrInvalidToken for garbage, got %v", err)
+	}
+}
+
+func Test03_ExpiredToken(t *testing.T) {
+	now := time.Now()
+	token, _ := Sign(secret, newClaims(now))
+	if _, err := Parse(secret, token, now.Add(2*time.Hour)); err != ErrExpiredToken {
+		t.Errorf("Expected ErrExpiredToken, got %v", err)
+	}
+}
@@ -3,20 +3,67 @@ package main
 import (
 	"be-binareversi/router"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"be-binareversi/auth"
 	"be-binareversi/db"
+	"be-binareversi/handler"
+	"be-binareversi/libs/chat"
+	"be-binareversi/service/lobby"
+	"be-binareversi/websocket"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// go run . migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
 	db.InitDatabase()
+	auth.SetSecret(os.Getenv("SESSION_SECRET"))
+
+	// ルーム操作は websocket と REST で同じサービスを共有する
+	registry := lobby.NewRegistry(db.Rooms)
+	if n, err := registry.Warm(); err != nil {
+		log.Fatalf("failed to load rooms: %v", err)
+	} else {
+		log.Printf("Loaded %d rooms.", n)
+	}
+	rooms := lobby.New(db.Players, registry, websocket.LobbyNotifier())
+	websocket.Rooms = rooms
+	handler.Rooms = rooms
+
+	// 切断後の猶予時間（秒）を環境変数で上書き
+	if v := os.Getenv("RECONNECT_GRACE_SECONDS"); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+			websocket.ReconnectGracePeriod = time.Duration(sec) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MATCH_TIMEOUT_SECONDS"); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+			websocket.MatchTimeout = time.Duration(sec) * time.Second
+		}
+	}
+
+	// チャットの設定
+	if os.Getenv("CHAT_PERSIST") == "1" {
+		websocket.ChatPersist = true
+		websocket.RestoreLobbyChat()
+	}
+	if v := os.Getenv("CHAT_BANNED_WORDS"); v != "" {
+		websocket.ChatFilter = chat.NewWordListFilter(strings.Split(v, ",")...)
+	}
 
 	go func() {
 		for {
 			time.Sleep(10 * time.Minute) // 10分おきにチェック
-			if err := db.DeleteOldRooms(360); err != nil {
+			if _, err := rooms.ExpireRooms(time.Now().Add(-360 * time.Minute)); err != nil {
 				log.Println("Failed to delete old rooms:", err)
 			} else {
 				log.Println("Old rooms cleanup completed.")
@@ -26,6 +73,9 @@ func main() {
 			} else {
 				log.Println("Inactive players cleanup completed.")
 			}
+			if err := db.DeleteExpiredSessions(); err != nil {
+				log.Println("Failed to delete expired sessions:", err)
+			}
 		}
 	}()
 
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"be-binareversi/db"
+	"be-binareversi/migration"
+)
+
+const migrateUsage = `usage: be-binareversi migrate <command>
+
+commands:
+  up [version]   未適用のマイグレーションを適用する（version 指定時はそこまで）
+  down [steps]   適用済みのマイグレーションを新しい順に巻き戻す（既定は1件）
+  status         各マイグレーションの適用状況を表示する`
+
+// migrate サブコマンドを実行する
+// @param args "migrate" より後の引数
+// @return int 終了コード
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	// 引数があれば数値として読み取る
+	number := func(def int) (int, bool) {
+		if len(args) < 2 {
+			return def, true
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			fmt.Fprintf(os.Stderr, "invalid number: %q\n", args[1])
+			return 0, false
+		}
+		return n, true
+	}
+
+	database, err := db.Open(db.ConfigFromEnv())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to database:", err)
+		return 1
+	}
+	m := migration.New(database)
+
+	switch args[0] {
+	case "up":
+		target, ok := number(0)
+		if !ok {
+			return 2
+		}
+		applied, err := m.Up(target)
+		for _, mig := range applied {
+			fmt.Printf("applied  %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+
+	case "down":
+		steps, ok := number(1)
+		if !ok {
+			return 2
+		}
+		rolledBack, err := m.Down(steps)
+		for _, mig := range rolledBack {
+			fmt.Printf("reverted %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("no applied migrations")
+		}
+
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-24s %s\n", st.Version, st.Name, state)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,165 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AutoMigrate で作られていたスキーマをそのまま引き継ぐ
+// 既存のデータベースではテーブルが既にあるため、無いテーブルは作成し、
+// あるテーブルには足りない列と索引だけを追加する（古いスキーマのままのデータベースに対応する）
+// （モデルの変更に引きずられないよう、この時点の定義を固定して持つ）
+
+type baselineRoom struct {
+	ID               string    `gorm:"not null;column:id;primaryKey"`
+	Player1          string    `gorm:"not null;column:player1"`
+	Player2          *string   `gorm:"column:player2"`
+	IsFull           bool      `gorm:"column:is_full"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime"`
+	Variant          string    `gorm:"column:variant;default:standard"`
+	TimeControl      string    `gorm:"column:time_control"`
+	InitialSeconds   int       `gorm:"column:initial_seconds"`
+	IncrementSeconds int       `gorm:"column:increment_seconds"`
+	MoveSeconds      int       `gorm:"column:move_seconds"`
+	IsPrivate        bool      `gorm:"column:is_private"`
+	InviteCodeHash   string    `gorm:"column:invite_code_hash;index"`
+	PasswordHash     string    `gorm:"column:password_hash"`
+	ReservedFor      *string   `gorm:"column:reserved_for"`
+}
+
+func (baselineRoom) TableName() string { return "rooms" }
+
+type baselinePlayer struct {
+	ID           string    `gorm:"not null;column:id;primaryKey"`
+	Name         string    `gorm:"not null;column:name"`
+	LastUsedAt   time.Time `gorm:"column:last_used_at;"`
+	IsBot        bool      `gorm:"column:is_bot"`
+	Username     *string   `gorm:"column:username;uniqueIndex"`
+	PasswordHash string    `gorm:"column:password_hash"`
+	Rating       float64   `gorm:"column:rating;default:1500"`
+	RatingRD     float64   `gorm:"column:rating_rd;default:350"`
+	Volatility   float64   `gorm:"column:volatility;default:0.06"`
+	RatedGames   int       `gorm:"column:rated_games;default:0"`
+}
+
+func (baselinePlayer) TableName() string { return "players" }
+
+type baselineChatMessage struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement;column:id"`
+	RoomID    string    `gorm:"column:room_id;index"`
+	Channel   string    `gorm:"not null;column:channel"`
+	PlayerID  string    `gorm:"not null;column:player_id"`
+	Text      string    `gorm:"not null;column:text"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (baselineChatMessage) TableName() string { return "chat_messages" }
+
+type baselineRatingHistory struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement;column:id"`
+	PlayerID   string    `gorm:"not null;column:player_id;index"`
+	RoomID     string    `gorm:"column:room_id"`
+	OpponentID string    `gorm:"column:opponent_id"`
+	Score      float64   `gorm:"column:score"`
+	Rating     float64   `gorm:"column:rating"`
+	RatingRD   float64   `gorm:"column:rating_rd"`
+	Volatility float64   `gorm:"column:volatility"`
+	Delta      float64   `gorm:"column:delta"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (baselineRatingHistory) TableName() string { return "rating_histories" }
+
+type baselineGameRecord struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement;column:id"`
+	RoomID     string    `gorm:"not null;column:room_id;index"`
+	BlackID    string    `gorm:"not null;column:black_id;index"`
+	WhiteID    string    `gorm:"not null;column:white_id;index"`
+	Winner     int       `gorm:"column:winner"`
+	Reason     string    `gorm:"column:reason"`
+	BlackDiscs int       `gorm:"column:black_discs"`
+	WhiteDiscs int       `gorm:"column:white_discs"`
+	BlackPlus  int       `gorm:"column:black_plus"`
+	BlackMul   int       `gorm:"column:black_mul"`
+	WhitePlus  int       `gorm:"column:white_plus"`
+	WhiteMul   int       `gorm:"column:white_mul"`
+	Actions    string    `gorm:"column:actions"`
+	StartedAt  time.Time `gorm:"column:started_at"`
+	EndedAt    time.Time `gorm:"column:ended_at"`
+}
+
+func (baselineGameRecord) TableName() string { return "game_records" }
+
+type baselineSession struct {
+	ID        string     `gorm:"not null;column:id;primaryKey"`
+	PlayerID  string     `gorm:"not null;column:player_id;index"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (baselineSession) TableName() string { return "sessions" }
+
+var baselineTables = []interface{}{
+	&baselineRoom{},
+	&baselinePlayer{},
+	&baselineChatMessage{},
+	&baselineRatingHistory{},
+	&baselineGameRecord{},
+	&baselineSession{},
+}
+
+var baseline = Migration{
+	Version: 1,
+	Name:    "baseline",
+	Up: func(tx *gorm.DB) error {
+		for _, table := range baselineTables {
+			if tx.Migrator().HasTable(table) {
+				if err := adoptTable(tx, table); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Migrator().CreateTable(table); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		for i := len(baselineTables) - 1; i >= 0; i-- {
+			if err := tx.Migrator().DropTable(baselineTables[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// 既存のテーブルに、固定した定義のうち足りない列と索引を追加する
+// （AutoMigrate と違い、既存の列の型は変えない）
+func adoptTable(tx *gorm.DB, table interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(table); err != nil {
+		return err
+	}
+	migrator := tx.Migrator()
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || migrator.HasColumn(table, field.DBName) {
+			continue
+		}
+		if err := migrator.AddColumn(table, field.Name); err != nil {
+			return err
+		}
+	}
+	for _, index := range stmt.Schema.ParseIndexes() {
+		if migrator.HasIndex(table, index.Name) {
+			continue
+		}
+		if err := migrator.CreateIndex(table, index.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package migration
+
+import "gorm.io/gorm"
+
+// ルームの進行状況（waiting / in_progress / finished）
+type roomStatusColumn struct {
+	Status string `gorm:"column:status;not null;default:waiting"`
+}
+
+func (roomStatusColumn) TableName() string { return "rooms" }
+
+var roomStatus = Migration{
+	Version: 2,
+	Name:    "room_status",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&roomStatusColumn{}, "Status"); err != nil {
+			return err
+		}
+		// 既に2人揃っているルームは、両者の準備完了を待つ状態から始める（対局はメモリ上にしか残らない）
+		return tx.Exec("UPDATE rooms SET status = ? WHERE is_full = ?", "ready", true).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&roomStatusColumn{}, "Status")
+	},
+}
@@ -0,0 +1,15 @@
+package migration
+
+import "gorm.io/gorm"
+
+// 公開ルーム一覧（is_private で絞り込み、created_at で並べる）のための索引
+var roomListIndex = Migration{
+	Version: 3,
+	Name:    "room_list_index",
+	Up: func(tx *gorm.DB) error {
+		return tx.Exec("CREATE INDEX IF NOT EXISTS idx_rooms_listing ON rooms (is_private, created_at, id)").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Exec("DROP INDEX IF EXISTS idx_rooms_listing").Error
+	},
+}
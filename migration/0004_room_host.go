@@ -0,0 +1,26 @@
+package migration
+
+import "gorm.io/gorm"
+
+// ルームのホスト（作成者。譲渡されると変わる）
+type roomHostColumn struct {
+	Host string `gorm:"column:host;not null;default:''"`
+}
+
+func (roomHostColumn) TableName() string { return "rooms" }
+
+var roomHost = Migration{
+	Version: 4,
+	Name:    "room_host",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&roomHostColumn{}, "Host"); err != nil {
+			return err
+		}
+		// これまでは作成者（Player1）がホストだった
+		return tx.Exec("UPDATE rooms SET host = player1").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		// SQLite の Migrator().DropColumn はテーブルを作り直し、0003 の索引を失うため直接削除する
+		return tx.Exec("ALTER TABLE rooms DROP COLUMN host").Error
+	},
+}
@@ -0,0 +1,23 @@
+package migration
+
+import "gorm.io/gorm"
+
+// ルームの先手の決め方
+type roomColorModeColumn struct {
+	ColorMode string `gorm:"column:color_mode;not null;default:alternate"`
+}
+
+func (roomColorModeColumn) TableName() string { return "rooms" }
+
+// 既存のルームはこれまでどおり作成者の先手で始め、再戦で入れ替える
+var roomColorMode = Migration{
+	Version: 5,
+	Name:    "room_color_mode",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&roomColorModeColumn{}, "ColorMode")
+	},
+	Down: func(tx *gorm.DB) error {
+		// 0004 と同じく、索引を残すため直接削除する
+		return tx.Exec("ALTER TABLE rooms DROP COLUMN color_mode").Error
+	},
+}
@@ -0,0 +1,46 @@
+package migration
+
+import "gorm.io/gorm"
+
+// ルームの開始局面の設定
+type roomOpeningColumns struct {
+	Opening  string `gorm:"column:opening;not null;default:standard"`
+	Handicap int    `gorm:"column:handicap;not null;default:0"`
+	Position string `gorm:"column:position;not null;default:''"`
+}
+
+func (roomOpeningColumns) TableName() string { return "rooms" }
+
+// 対局記録の開始局面（手順を再生するのに使う）
+type recordStartPositionColumn struct {
+	StartPosition string `gorm:"column:start_position;not null;default:''"`
+}
+
+func (recordStartPositionColumn) TableName() string { return "game_records" }
+
+var opening = Migration{
+	Version: 6,
+	Name:    "opening",
+	Up: func(tx *gorm.DB) error {
+		for _, field := range []string{"Opening", "Handicap", "Position"} {
+			if err := tx.Migrator().AddColumn(&roomOpeningColumns{}, field); err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().AddColumn(&recordStartPositionColumn{}, "StartPosition")
+	},
+	Down: func(tx *gorm.DB) error {
+		// 0004 と同じく、索引を残すため直接削除する
+		for _, stmt := range []string{
+			"ALTER TABLE game_records DROP COLUMN start_position",
+			"ALTER TABLE rooms DROP COLUMN position",
+			"ALTER TABLE rooms DROP COLUMN handicap",
+			"ALTER TABLE rooms DROP COLUMN opening",
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
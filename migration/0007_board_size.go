@@ -0,0 +1,22 @@
+package migration
+
+import "gorm.io/gorm"
+
+// ルームの盤面の大きさ（既存のルームは 8x8）
+type roomBoardSizeColumn struct {
+	BoardSize int `gorm:"column:board_size;not null;default:8"`
+}
+
+func (roomBoardSizeColumn) TableName() string { return "rooms" }
+
+var boardSize = Migration{
+	Version: 7,
+	Name:    "board_size",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&roomBoardSizeColumn{}, "BoardSize")
+	},
+	Down: func(tx *gorm.DB) error {
+		// 0004 と同じく、索引を残すため直接削除する
+		return tx.Exec("ALTER TABLE rooms DROP COLUMN board_size").Error
+	},
+}
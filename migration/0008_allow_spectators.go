@@ -0,0 +1,22 @@
+package migration
+
+import "gorm.io/gorm"
+
+// 観戦を許可するかどうか（既存のルームは許可しない）
+type roomAllowSpectatorsColumn struct {
+	AllowSpectators bool `gorm:"column:allow_spectators;not null;default:false"`
+}
+
+func (roomAllowSpectatorsColumn) TableName() string { return "rooms" }
+
+var allowSpectators = Migration{
+	Version: 8,
+	Name:    "allow_spectators",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&roomAllowSpectatorsColumn{}, "AllowSpectators")
+	},
+	Down: func(tx *gorm.DB) error {
+		// 0004 と同じく、索引を残すため直接削除する
+		return tx.Exec("ALTER TABLE rooms DROP COLUMN allow_spectators").Error
+	},
+}
@@ -0,0 +1,13 @@
+package migration
+
+// 適用するマイグレーションの一覧（バージョンは連番で、適用済みのものは変更しない）
+var All = []Migration{
+	baseline,
+	roomStatus,
+	roomListIndex,
+	roomHost,
+	roomColorMode,
+	opening,
+	boardSize,
+	allowSpectators,
+}
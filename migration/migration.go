@@ -0,0 +1,163 @@
+// Package migration は番号付きのスキーマ変更（up / down）を適用・巻き戻しする
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 1件のスキーマ変更
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// 適用済みのマイグレーション（schema_migrations テーブル）
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false;column:version"`
+	Name      string    `gorm:"not null;column:name"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// マイグレーションごとの適用状況
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // 未適用なら nil
+}
+
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+// Migrator は登録済みのマイグレーションを順に適用する
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// Migrator を作成する
+// @param db 接続済みの *gorm.DB
+// @param migrations 適用対象（省略時は All）
+func New(db *gorm.DB, migrations ...Migration) *Migrator {
+	if len(migrations) == 0 {
+		migrations = All
+	}
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Migrator{db: db, migrations: sorted}
+}
+
+// schema_migrations を用意し、適用済みのバージョンを返す
+func (m *Migrator) applied() (map[int]SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var rows []SchemaMigration
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// 未適用のマイグレーションを target まで順に適用する
+// @param target 適用する最大バージョン（0 なら全て）
+// @return []Migration 適用したマイグレーション
+func (m *Migrator) Up(target int) ([]Migration, error) {
+	if target != 0 && m.find(target) == nil {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, mig := range m.migrations {
+		if target != 0 && mig.Version > target {
+			break
+		}
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// 適用済みのマイグレーションを新しいものから steps 件巻き戻す
+// @param steps 巻き戻す件数
+// @return []Migration 巻き戻したマイグレーション
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", mig.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback %04d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// 各マイグレーションの適用状況を返す
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			appliedAt := row.AppliedAt
+			st.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package migration
+
+import (
+	"be-binareversi/model"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	database, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return database
+}
+
+type note struct {
+	ID   uint `gorm:"primaryKey"`
+	Text string
+}
+
+// テスト用のマイグレーション（2: notes 作成, 3: notes.text にインデックス）
+var testMigrations = []Migration{
+	{
+		Version: 3,
+		Name:    "index_notes",
+		Up:      func(tx *gorm.DB) error { return tx.Exec("CREATE INDEX idx_notes_text ON notes(text)").Error },
+		Down:    func(tx *gorm.DB) error { return tx.Exec("DROP INDEX idx_notes_text").Error },
+	},
+	{
+		Version: 2,
+		Name:    "create_notes",
+		Up:      func(tx *gorm.DB) error { return tx.Migrator().CreateTable(&note{}) },
+		Down:    func(tx *gorm.DB) error { return tx.Migrator().DropTable(&note{}) },
+	},
+}
+
+func Test01_UpAppliesInOrder(t *testing.T) {
+	database := openTestDB(t)
+	m := New(database, testMigrations...)
+
+	applied, err := m.Up(0)
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if len(applied) != 2 || applied[0].Version != 2 || applied[1].Version != 3 {
+		t.Errorf("Expected versions 2, 3 in order, got %+v", applied)
+	}
+	if !database.Migrator().HasIndex(&note{}, "idx_notes_text") {
+		t.Error("Expected index to exist")
+	}
+
+	again, err := m.Up(0)
+	if err != nil || len(again) != 0 {
+		t.Errorf("Expected no pending migrations, got %d, %v", len(again), err)
+	}
+}
+
+func Test02_UpToTargetAndStatus(t *testing.T) {
+	m := New(openTestDB(t), testMigrations...)
+
+	if _, err := m.Up(2); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	statuses, _ := m.Status()
+	if len(statuses) != 2 || statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Errorf("Expected only version 2 applied, got %+v", statuses)
+	}
+	if _, err := m.Up(9); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Expected ErrUnknownVersion, got %v", err)
+	}
+}
+
+func Test03_DownRollsBackNewestFirst(t *testing.T) {
+	database := openTestDB(t)
+	m := New(database, testMigrations...)
+	m.Up(0)
+
+	reverted, err := m.Down(1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != 3 {
+		t.Fatalf("Expected version 3 reverted, got %+v, %v", reverted, err)
+	}
+	if database.Migrator().HasIndex(&note{}, "idx_notes_text") {
+		t.Error("Expected index to be dropped")
+	}
+
+	m.Down(5)
+	if database.Migrator().HasTable(&note{}) {
+		t.Error("Expected notes table to be dropped")
+	}
+	if statuses, _ := m.Status(); statuses[0].AppliedAt != nil {
+		t.Error("Expected no applied migrations")
+	}
+}
+
+func Test04_FailedMigrationIsNotRecorded(t *testing.T) {
+	database := openTestDB(t)
+	broken := Migration{
+		Version: 4,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			tx.Migrator().CreateTable(&note{})
+			return errors.New("boom")
+		},
+		Down: func(tx *gorm.DB) error { return nil },
+	}
+	m := New(database, broken)
+
+	if _, err := m.Up(0); err == nil {
+		t.Fatal("Expected error from broken migration")
+	}
+	if database.Migrator().HasTable(&note{}) {
+		t.Error("Expected table creation to be rolled back")
+	}
+	if statuses, _ := m.Status(); statuses[0].AppliedAt != nil {
+		t.Error("Expected broken migration to stay pending")
+	}
+}
+
+func Test05_AdoptsAutoMigratedSchema(t *testing.T) {
+	database := openTestDB(t)
+	// マイグレーション導入前の、最初の AutoMigrate で作られたままのデータベース
+	for _, stmt := range []string{
+		"CREATE TABLE rooms (id text NOT NULL, player1 text NOT NULL, player2 text, is_full numeric, created_at datetime, PRIMARY KEY (id))",
+		"CREATE TABLE players (id text NOT NULL, name text NOT NULL, last_used_at datetime, PRIMARY KEY (id))",
+		"INSERT INTO players (id, name, last_used_at) VALUES ('p1', 'alice', CURRENT_TIMESTAMP)",
+		"INSERT INTO rooms (id, player1, is_full, created_at) VALUES ('r1', 'p1', true, CURRENT_TIMESTAMP)",
+	} {
+		if err := database.Exec(stmt).Error; err != nil {
+			t.Fatalf("Seeding failed: %v", err)
+		}
+	}
+
+	if _, err := New(database).Up(0); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	var player model.Player
+	if err := database.First(&player, "id = ?", "p1").Error; err != nil {
+		t.Errorf("Expected existing player to remain, got %v", err)
+	}
+	var room model.Room
+	if err := database.First(&room, "id = ?", "r1").Error; err != nil || room.Status != "ready" {
+		t.Errorf("Expected full room to be backfilled as ready, got %q, %v", room.Status, err)
+	}
+	if room.Host != "p1" {
+		t.Errorf("Expected creator to be backfilled as host, got %q", room.Host)
+	}
+	if room.ColorMode != "alternate" {
+		t.Errorf("Expected existing room to keep alternating colors, got %q", room.ColorMode)
+	}
+	if room.Opening != "standard" {
+		t.Errorf("Expected existing room to use the standard opening, got %q", room.Opening)
+	}
+	if room.BoardSize != 8 {
+		t.Errorf("Expected existing room to use an 8x8 board, got %d", room.BoardSize)
+	}
+	// 後から追加された列と索引も補われる
+	for table, columns := range map[string][]string{
+		"rooms":   {"is_private", "invite_code_hash", "variant"},
+		"players": {"is_bot", "username", "rating", "rated_games"},
+	} {
+		for _, column := range columns {
+			if !database.Migrator().HasColumn(table, column) {
+				t.Errorf("Expected column %s.%s to be added", table, column)
+			}
+		}
+	}
+	if player.Rating != 1500 {
+		t.Errorf("Expected existing player to get the default rating, got %v", player.Rating)
+	}
+	for _, table := range []string{"chat_messages", "rating_histories", "game_records", "sessions"} {
+		if !database.Migrator().HasTable(table) {
+			t.Errorf("Expected table %s to be created", table)
+		}
+	}
+}
+
+func Test06_AllMigrationsRollBack(t *testing.T) {
+	database := openTestDB(t)
+	m := New(database)
+	if _, err := m.Up(0); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	// 新しいものから1つずつ戻し、そのマイグレーションの変更が消えることを確かめる
+	schema := database.Migrator()
+	steps := []struct {
+		name    string
+		applied func() bool
+	}{
+		{"allow_spectators", func() bool { return schema.HasColumn("rooms", "allow_spectators") }},
+		{"board_size", func() bool { return schema.HasColumn("rooms", "board_size") }},
+		{"opening", func() bool {
+			return schema.HasColumn("rooms", "opening") || schema.HasColumn("game_records", "start_position")
+		}},
+		{"room_color_mode", func() bool { return schema.HasColumn("rooms", "color_mode") }},
+		{"room_host", func() bool { return schema.HasColumn("rooms", "host") }},
+		{"room_list_index", func() bool { return schema.HasIndex("rooms", "idx_rooms_listing") }},
+		{"room_status", func() bool { return schema.HasColumn("rooms", "status") }},
+		{"baseline", func() bool { return schema.HasTable("rooms") }},
+	}
+	if len(steps) != len(All) {
+		t.Fatalf("Expected a rollback check for each of %d migrations, got %d", len(All), len(steps))
+	}
+	for _, step := range steps {
+		if !step.applied() {
+			t.Errorf("Expected %s to be applied", step.name)
+		}
+		if _, err := m.Down(1); err != nil {
+			t.Fatalf("Down %s failed: %v", step.name, err)
+		}
+		if step.applied() {
+			t.Errorf("Expected %s to be rolled back", step.name)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type ChatMessage struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	RoomID    string    `json:"roomID" gorm:"column:room_id;index"` // 空ならロビー
+	Channel   string    `json:"channel" gorm:"not null;column:channel"`
+	PlayerID  string    `json:"playerID" gorm:"not null;column:player_id"`
+	Text      string    `json:"text" gorm:"not null;column:text"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+}
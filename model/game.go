@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// 終了した対局の記録
+type GameRecord struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	RoomID        string    `json:"roomID" gorm:"not null;column:room_id;index"`
+	BlackID       string    `json:"blackID" gorm:"not null;column:black_id;index"`
+	WhiteID       string    `json:"whiteID" gorm:"not null;column:white_id;index"`
+	Winner        int       `json:"winner" gorm:"column:winner"` // Black=1, White=0, 引き分け=-1
+	Reason        string    `json:"reason" gorm:"column:reason"`
+	BlackDiscs    int       `json:"blackDiscs" gorm:"column:black_discs"`
+	WhiteDiscs    int       `json:"whiteDiscs" gorm:"column:white_discs"`
+	BlackPlus     int       `json:"blackPlus" gorm:"column:black_plus"` // 演算子の使用回数
+	BlackMul      int       `json:"blackMul" gorm:"column:black_mul"`
+	WhitePlus     int       `json:"whitePlus" gorm:"column:white_plus"`
+	WhiteMul      int       `json:"whiteMul" gorm:"column:white_mul"`
+	Actions       string    `json:"actions" gorm:"column:actions"`                                            // 手順と消費時間（JSON）
+	StartPosition string    `json:"startPosition,omitempty" gorm:"column:start_position;not null;default:''"` // 標準以外の開始局面（局面文字列）
+	StartedAt     time.Time `json:"startedAt" gorm:"column:started_at"`
+	EndedAt       time.Time `json:"endedAt" gorm:"column:ended_at"`
+}
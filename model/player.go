@@ -6,4 +6,15 @@ type Player struct {
 	ID         string    `json:"id" gorm:"not null;column:id;primaryKey"`
 	Name       string    `json:"name" gorm:"not null;column:name"`
 	LastUsedAt time.Time `json:"lastUsedAt" gorm:"column:last_used_at;"`
+	IsBot      bool      `json:"isBot" gorm:"column:is_bot"` // サーバー側のAIプレイヤー
+
+	// アカウント登録済みの場合のみ設定（ゲストは nil）
+	Username     *string `json:"username,omitempty" gorm:"column:username;uniqueIndex"`
+	PasswordHash string  `json:"-" gorm:"column:password_hash"`
+
+	// Glicko-2 レーティング
+	Rating     float64 `json:"rating" gorm:"column:rating;default:1500"`
+	RatingRD   float64 `json:"ratingRD" gorm:"column:rating_rd;default:350"`
+	Volatility float64 `json:"volatility" gorm:"column:volatility;default:0.06"`
+	RatedGames int     `json:"ratedGames" gorm:"column:rated_games;default:0"`
 }
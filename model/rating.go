@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// レーティングの変動履歴（1局ごとに1行）
+type RatingHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	PlayerID   string    `json:"playerID" gorm:"not null;column:player_id;index"`
+	RoomID     string    `json:"roomID" gorm:"column:room_id"`
+	OpponentID string    `json:"opponentID" gorm:"column:opponent_id"`
+	Score      float64   `json:"score" gorm:"column:score"` // 勝ち=1, 引き分け=0.5, 負け=0
+	Rating     float64   `json:"rating" gorm:"column:rating"`
+	RatingRD   float64   `json:"ratingRD" gorm:"column:rating_rd"`
+	Volatility float64   `json:"volatility" gorm:"column:volatility"`
+	Delta      float64   `json:"delta" gorm:"column:delta"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+}
@@ -6,8 +6,35 @@ type Room struct {
 	ID        string    `json:"id" gorm:"not null;column:id;primaryKey"`
 	Player1   string    `json:"player1" gorm:"not null;column:player1"`
 	Player2   *string   `json:"player2,omitempty" gorm:"column:player2"`
+	Host      string    `json:"host" gorm:"column:host;not null;default:''"` // ホスト（作成者。譲渡されると変わる）
 	IsFull    bool      `json:"isFull" gorm:"column:is_full"`
 	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
-}
+	Status    string    `json:"status" gorm:"column:status;not null;default:waiting"` // waiting / ready / in_progress / finished / abandoned
+
+	// ルール（standard / blitz / rapid / custom）と持ち時間の設定（TimeControl が空なら時間制限なし）
+	Variant          string `json:"variant,omitempty" gorm:"column:variant;default:standard"`
+	TimeControl      string `json:"timeControl,omitempty" gorm:"column:time_control"`
+	InitialSeconds   int    `json:"initialSeconds,omitempty" gorm:"column:initial_seconds"`
+	IncrementSeconds int    `json:"incrementSeconds,omitempty" gorm:"column:increment_seconds"`
+	MoveSeconds      int    `json:"moveSeconds,omitempty" gorm:"column:move_seconds"`
+
+	// 先手の決め方（black / white / random / alternate。black と white は作成者の色）
+	ColorMode string `json:"colorMode,omitempty" gorm:"column:color_mode;not null;default:alternate"`
+
+	// 盤面の一辺のマス数（偶数）
+	BoardSize int `json:"boardSize,omitempty" gorm:"column:board_size;not null;default:8"`
 
-var Rooms = map[string]*Room{}
+	// 開始局面（standard / handicap / random / custom）。Handicap は角の数、Position は custom の局面文字列
+	Opening  string `json:"opening,omitempty" gorm:"column:opening;not null;default:standard"`
+	Handicap int    `json:"handicap,omitempty" gorm:"column:handicap;not null;default:0"`
+	Position string `json:"position,omitempty" gorm:"column:position;not null;default:''"`
+
+	// 着席していないプレイヤーの観戦を許可するか（非公開ルームは常に不可）
+	AllowSpectators bool `json:"allowSpectators" gorm:"column:allow_spectators;not null;default:false"`
+
+	// 非公開ルームの設定（招待コードとパスワードはハッシュのみ保存）
+	IsPrivate      bool    `json:"isPrivate" gorm:"column:is_private"`
+	InviteCodeHash string  `json:"-" gorm:"column:invite_code_hash;index"`
+	PasswordHash   string  `json:"-" gorm:"column:password_hash"`
+	ReservedFor    *string `json:"reservedFor,omitempty" gorm:"column:reserved_for"` // 参加できるプレイヤーを限定する場合のID
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ログインセッション（トークンの失効管理に使う）
+type Session struct {
+	ID        string     `json:"id" gorm:"not null;column:id;primaryKey"`
+	PlayerID  string     `json:"playerID" gorm:"not null;column:player_id;index"`
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"column:expires_at"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty" gorm:"column:revoked_at"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+}
@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"be-binareversi/model"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORM（SQLite / PostgreSQL）を使うリポジトリを作成する
+// @param db 接続済みの *gorm.DB
+// @return Repositories
+func NewGorm(db *gorm.DB) Repositories {
+	return Repositories{
+		Players:  &gormPlayers{db: db},
+		Rooms:    &gormRooms{db: db},
+		Games:    &gormGames{db: db},
+		Sessions: &gormSessions{db: db},
+		Ratings:  &gormRatings{db: db},
+		Chats:    &gormChats{db: db},
+	}
+}
+
+// gorm.ErrRecordNotFound を ErrNotFound に置き換える
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type gormPlayers struct {
+	db *gorm.DB
+}
+
+func (r *gormPlayers) Create(player *model.Player) error {
+	return r.db.Create(player).Error
+}
+
+func (r *gormPlayers) GetByID(id string) (*model.Player, error) {
+	var player model.Player
+	if err := r.db.First(&player, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &player, nil
+}
+
+func (r *gormPlayers) GetByUsername(username string) (*model.Player, error) {
+	var player model.Player
+	if err := r.db.First(&player, "username = ?", username).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &player, nil
+}
+
+func (r *gormPlayers) Update(player *model.Player) error {
+	return r.db.Save(player).Error
+}
+
+// 指定したカラムだけを更新する
+func (r *gormPlayers) updateColumns(id string, columns map[string]interface{}) error {
+	return r.db.Model(&model.Player{}).Where("id = ?", id).Updates(columns).Error
+}
+
+func (r *gormPlayers) Touch(id string, at time.Time) error {
+	return r.updateColumns(id, map[string]interface{}{"last_used_at": at})
+}
+
+func (r *gormPlayers) Rename(id, name string, at time.Time) error {
+	return r.updateColumns(id, map[string]interface{}{"name": name, "last_used_at": at})
+}
+
+func (r *gormPlayers) SetCredentials(id, username, passwordHash string, at time.Time) error {
+	return r.updateColumns(id, map[string]interface{}{
+		"username":      username,
+		"password_hash": passwordHash,
+		"last_used_at":  at,
+	})
+}
+
+func (r *gormPlayers) Delete(id string) error {
+	return r.db.Delete(&model.Player{}, "id = ?", id).Error
+}
+
+func (r *gormPlayers) DeleteInactive(before time.Time) error {
+	return r.db.Where("last_used_at < ? AND username IS NULL", before).Delete(&model.Player{}).Error
+}
+
+type gormRooms struct {
+	db *gorm.DB
+}
+
+func (r *gormRooms) Create(room *model.Room) error {
+	return r.db.Create(room).Error
+}
+
+func (r *gormRooms) GetByID(id string) (*model.Room, error) {
+	var room model.Room
+	if err := r.db.First(&room, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &room, nil
+}
+
+func (r *gormRooms) GetByInviteCodeHash(hash string) (*model.Room, error) {
+	var room model.Room
+	if err := r.db.First(&room, "invite_code_hash = ?", hash).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &room, nil
+}
+
+func (r *gormRooms) GetByPlayerID(player1ID string) ([]*model.Room, error) {
+	var rooms []*model.Room
+	if err := r.db.Where("player1 = ?", player1ID).Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *gormRooms) GetAll() ([]*model.Room, error) {
+	var rooms []*model.Room
+	if err := r.db.Order("created_at").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *gormRooms) ListPublic(q RoomQuery) ([]*RoomListing, error) {
+	tx := r.db.Table("rooms").
+		Select("rooms.*, p1.name AS player1_name, p2.name AS player2_name, COALESCE(p1.rating, 0) AS player1_rating").
+		Joins("LEFT JOIN players p1 ON p1.id = rooms.player1").
+		Joins("LEFT JOIN players p2 ON p2.id = rooms.player2").
+		Where("rooms.is_private = ?", false)
+
+	switch q.Status {
+	case "open":
+		tx = tx.Where("rooms.is_full = ?", false)
+	case "full":
+		tx = tx.Where("rooms.is_full = ?", true)
+	}
+	if q.Variant != "" {
+		tx = tx.Where("rooms.variant = ?", q.Variant)
+	}
+	if q.MinRating > 0 {
+		tx = tx.Where("p1.rating >= ?", q.MinRating)
+	}
+	if q.MaxRating > 0 {
+		tx = tx.Where("p1.rating <= ?", q.MaxRating)
+	}
+
+	key, dir, op := "rooms.created_at", "ASC", ">"
+	if !q.byCreatedAt() {
+		key = "COALESCE(p1.rating, 0)"
+	}
+	if q.descending() {
+		dir, op = "DESC", "<"
+	}
+	if q.After != nil {
+		var value interface{} = q.After.CreatedAt
+		if !q.byCreatedAt() {
+			value = q.After.Rating
+		}
+		tx = tx.Where(fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND rooms.id %[2]s ?)", key, op), value, value, q.After.ID)
+	}
+	tx = tx.Order(fmt.Sprintf("%s %s, rooms.id %s", key, dir, dir))
+	if q.Offset > 0 {
+		tx = tx.Offset(q.Offset)
+	}
+	if q.Limit > 0 {
+		tx = tx.Limit(q.Limit)
+	}
+
+	var listings []*RoomListing
+	if err := tx.Scan(&listings).Error; err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+func (r *gormRooms) Update(room *model.Room) error {
+	return r.db.Save(room).Error
+}
+
+func (r *gormRooms) Delete(id string) error {
+	return r.db.Delete(&model.Room{}, "id = ?", id).Error
+}
+
+func (r *gormRooms) DeleteCreatedBefore(before time.Time) error {
+	return r.db.Where("created_at < ?", before).Delete(&model.Room{}).Error
+}
+
+type gormGames struct {
+	db *gorm.DB
+}
+
+func (r *gormGames) Create(record *model.GameRecord) error {
+	return r.db.Create(record).Error
+}
+
+func (r *gormGames) ListByPlayer(playerID string, limit, offset int) ([]*model.GameRecord, error) {
+	var records []*model.GameRecord
+	err := r.db.Where("black_id = ? OR white_id = ?", playerID, playerID).
+		Order("id desc").Limit(limit).Offset(offset).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *gormGames) CountByPlayer(playerID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.GameRecord{}).
+		Where("black_id = ? OR white_id = ?", playerID, playerID).Count(&count).Error
+	return count, err
+}
+
+type gormSessions struct {
+	db *gorm.DB
+}
+
+func (r *gormSessions) Create(session *model.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *gormSessions) GetByID(id string) (*model.Session, error) {
+	var session model.Session
+	if err := r.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &session, nil
+}
+
+func (r *gormSessions) Revoke(id string, at time.Time) error {
+	return r.db.Model(&model.Session{}).Where("id = ?", id).Update("revoked_at", at).Error
+}
+
+func (r *gormSessions) RevokeByPlayer(playerID string, at time.Time) error {
+	return r.db.Model(&model.Session{}).
+		Where("player_id = ? AND revoked_at IS NULL", playerID).Update("revoked_at", at).Error
+}
+
+func (r *gormSessions) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&model.Session{}).Error
+}
+
+type gormRatings struct {
+	db *gorm.DB
+	// SQLite は読み込み後の書き込みで同時実行のトランザクション同士がロック待ちにならず失敗するため、プロセス内で直列化する
+	sqliteMu sync.Mutex
+}
+
+func (r *gormRatings) Save(playerIDs []string, compute RatingCompute) error {
+	postgres := r.db.Dialector.Name() == "postgres"
+	if !postgres {
+		r.sqliteMu.Lock()
+		defer r.sqliteMu.Unlock()
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 同じ2人の対局が同時に終わってもデッドロックしないよう、ID順にロックする
+		sorted := append([]string(nil), playerIDs...)
+		sort.Strings(sorted)
+		byID := make(map[string]*model.Player, len(sorted))
+		for _, id := range sorted {
+			query := tx
+			if postgres {
+				query = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+			}
+			var player model.Player
+			if err := query.First(&player, "id = ?", id).Error; err != nil {
+				return notFound(err)
+			}
+			byID[id] = &player
+		}
+		players := make([]*model.Player, len(playerIDs))
+		for i, id := range playerIDs {
+			players[i] = byID[id]
+		}
+
+		history := compute(players)
+		if len(history) == 0 {
+			return nil
+		}
+		for _, p := range players {
+			if err := tx.Model(&model.Player{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+				"rating":      p.Rating,
+				"rating_rd":   p.RatingRD,
+				"volatility":  p.Volatility,
+				"rated_games": p.RatedGames,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&history).Error
+	})
+}
+
+func (r *gormRatings) Leaderboard(limit, offset int) ([]*model.Player, error) {
+	var players []*model.Player
+	err := r.db.Where("is_bot = ? AND rated_games > 0", false).
+		Order("rating desc").Order("id").Limit(limit).Offset(offset).Find(&players).Error
+	if err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+func (r *gormRatings) History(playerID string, limit, offset int) ([]*model.RatingHistory, error) {
+	var history []*model.RatingHistory
+	err := r.db.Where("player_id = ?", playerID).
+		Order("id desc").Limit(limit).Offset(offset).Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+type gormChats struct {
+	db *gorm.DB
+}
+
+func (r *gormChats) Create(msg *model.ChatMessage) error {
+	return r.db.Create(msg).Error
+}
+
+func (r *gormChats) ListRecent(roomID string, limit int) ([]*model.ChatMessage, error) {
+	var msgs []*model.ChatMessage
+	err := r.db.Where("room_id = ?", roomID).Order("id desc").Limit(limit).Find(&msgs).Error
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+	return msgs, nil
+}
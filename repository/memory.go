@@ -0,0 +1,497 @@
+package repository
+
+import (
+	"be-binareversi/model"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// メモリ上に保持するリポジトリを作成する（テスト用）
+// 取得したレコードはコピーなので、変更は Update するまで反映されない
+func NewMemory() Repositories {
+	players := &memoryPlayers{players: map[string]model.Player{}}
+	return Repositories{
+		Players:  players,
+		Rooms:    &memoryRooms{rooms: map[string]model.Room{}, players: players},
+		Games:    &memoryGames{},
+		Sessions: &memorySessions{sessions: map[string]model.Session{}},
+		Ratings:  &memoryRatings{players: players},
+		Chats:    &memoryChats{},
+	}
+}
+
+// limit・offset を GORM と同じ規則で範囲に変換する（負の値は指定なし）
+// @param n 全件数
+// @return int, int 取り出す範囲 [start, end)
+func pageRange(n, limit, offset int) (int, int) {
+	start := offset
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end := n
+	if limit >= 0 && start+limit < n {
+		end = start + limit
+	}
+	return start, end
+}
+
+var errDuplicate = errors.New("duplicate key")
+
+type memoryPlayers struct {
+	mu      sync.RWMutex
+	players map[string]model.Player
+}
+
+func (r *memoryPlayers) Create(player *model.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.players[player.ID]; ok {
+		return errDuplicate
+	}
+	if player.Rating == 0 {
+		// GORM のカラム既定値に合わせる
+		player.Rating, player.RatingRD, player.Volatility = 1500, 350, 0.06
+	}
+	r.players[player.ID] = *player
+	return nil
+}
+
+func (r *memoryPlayers) GetByID(id string) (*model.Player, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	player, ok := r.players[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &player, nil
+}
+
+func (r *memoryPlayers) GetByUsername(username string) (*model.Player, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, player := range r.players {
+		if player.Username != nil && *player.Username == username {
+			return &player, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPlayers) Update(player *model.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.players[player.ID] = *player
+	return nil
+}
+
+// 保存済みのプレイヤーを書き換える
+func (r *memoryPlayers) modify(id string, apply func(player *model.Player)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if player, ok := r.players[id]; ok {
+		apply(&player)
+		r.players[id] = player
+	}
+}
+
+func (r *memoryPlayers) Touch(id string, at time.Time) error {
+	r.modify(id, func(player *model.Player) {
+		player.LastUsedAt = at
+	})
+	return nil
+}
+
+func (r *memoryPlayers) Rename(id, name string, at time.Time) error {
+	r.modify(id, func(player *model.Player) {
+		player.Name, player.LastUsedAt = name, at
+	})
+	return nil
+}
+
+func (r *memoryPlayers) SetCredentials(id, username, passwordHash string, at time.Time) error {
+	if other, err := r.GetByUsername(username); err == nil && other.ID != id {
+		return errDuplicate
+	}
+	r.modify(id, func(player *model.Player) {
+		player.Username, player.PasswordHash, player.LastUsedAt = &username, passwordHash, at
+	})
+	return nil
+}
+
+func (r *memoryPlayers) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.players, id)
+	return nil
+}
+
+func (r *memoryPlayers) DeleteInactive(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, player := range r.players {
+		if player.Username == nil && player.LastUsedAt.Before(before) {
+			delete(r.players, id)
+		}
+	}
+	return nil
+}
+
+type memoryRooms struct {
+	mu      sync.RWMutex
+	rooms   map[string]model.Room
+	players *memoryPlayers // ListPublic で名前・レーティングを引く
+}
+
+func (r *memoryRooms) Create(room *model.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rooms[room.ID]; ok {
+		return errDuplicate
+	}
+	if room.CreatedAt.IsZero() {
+		room.CreatedAt = time.Now()
+	}
+	if room.Status == "" {
+		// GORM のカラム既定値に合わせる
+		room.Status = "waiting"
+	}
+	r.rooms[room.ID] = *room
+	return nil
+}
+
+func (r *memoryRooms) GetByID(id string) (*model.Room, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	room, ok := r.rooms[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &room, nil
+}
+
+func (r *memoryRooms) GetByInviteCodeHash(hash string) (*model.Room, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, room := range r.rooms {
+		if room.InviteCodeHash != "" && room.InviteCodeHash == hash {
+			return &room, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// 条件に合うルームを作成順に返す
+func (r *memoryRooms) filter(match func(model.Room) bool) []*model.Room {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rooms := []*model.Room{}
+	for _, room := range r.rooms {
+		if match(room) {
+			room := room
+			rooms = append(rooms, &room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].CreatedAt.Before(rooms[j].CreatedAt) })
+	return rooms
+}
+
+func (r *memoryRooms) GetByPlayerID(player1ID string) ([]*model.Room, error) {
+	return r.filter(func(room model.Room) bool { return room.Player1 == player1ID }), nil
+}
+
+func (r *memoryRooms) GetAll() ([]*model.Room, error) {
+	return r.filter(func(model.Room) bool { return true }), nil
+}
+
+func (r *memoryRooms) ListPublic(q RoomQuery) ([]*RoomListing, error) {
+	rooms := r.filter(func(room model.Room) bool {
+		return !room.IsPrivate &&
+			(q.Status != "open" || !room.IsFull) &&
+			(q.Status != "full" || room.IsFull) &&
+			(q.Variant == "" || room.Variant == q.Variant)
+	})
+
+	r.players.mu.RLock()
+	listings := []*RoomListing{}
+	for _, room := range rooms {
+		l := &RoomListing{Room: *room}
+		if p, ok := r.players.players[room.Player1]; ok {
+			l.Player1Name, l.Player1Rating = p.Name, p.Rating
+		}
+		if room.Player2 != nil {
+			l.Player2Name = r.players.players[*room.Player2].Name
+		}
+		if (q.MinRating > 0 && l.Player1Rating < q.MinRating) || (q.MaxRating > 0 && l.Player1Rating > q.MaxRating) {
+			continue
+		}
+		listings = append(listings, l)
+	}
+	r.players.mu.RUnlock()
+
+	// 並び順のキー、ID の順に比べる
+	before := func(a, b RoomCursor) bool {
+		less, greater := a.CreatedAt.Before(b.CreatedAt), a.CreatedAt.After(b.CreatedAt)
+		if !q.byCreatedAt() {
+			less, greater = a.Rating < b.Rating, a.Rating > b.Rating
+		}
+		if q.descending() {
+			less, greater = greater, less
+		}
+		if less || greater {
+			return less
+		}
+		if q.descending() {
+			return a.ID > b.ID
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(listings, func(i, j int) bool { return before(listings[i].Cursor(), listings[j].Cursor()) })
+
+	if q.After != nil {
+		i := sort.Search(len(listings), func(i int) bool { return before(*q.After, listings[i].Cursor()) })
+		listings = listings[i:]
+	}
+	if q.Offset >= len(listings) {
+		return []*RoomListing{}, nil
+	}
+	listings = listings[q.Offset:]
+	if q.Limit > 0 && len(listings) > q.Limit {
+		listings = listings[:q.Limit]
+	}
+	return listings, nil
+}
+
+func (r *memoryRooms) Update(room *model.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rooms[room.ID] = *room
+	return nil
+}
+
+func (r *memoryRooms) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rooms, id)
+	return nil
+}
+
+func (r *memoryRooms) DeleteCreatedBefore(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, room := range r.rooms {
+		if room.CreatedAt.Before(before) {
+			delete(r.rooms, id)
+		}
+	}
+	return nil
+}
+
+type memoryGames struct {
+	mu      sync.RWMutex
+	records []model.GameRecord
+}
+
+func (r *memoryGames) Create(record *model.GameRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record.ID = uint(len(r.records) + 1)
+	r.records = append(r.records, *record)
+	return nil
+}
+
+// プレイヤーの対局記録を新しい順に返す（mu を保持した状態で呼ぶ）
+func (r *memoryGames) byPlayer(playerID string) []*model.GameRecord {
+	records := []*model.GameRecord{}
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if record := r.records[i]; record.BlackID == playerID || record.WhiteID == playerID {
+			records = append(records, &record)
+		}
+	}
+	return records
+}
+
+func (r *memoryGames) ListByPlayer(playerID string, limit, offset int) ([]*model.GameRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	records := r.byPlayer(playerID)
+	start, end := pageRange(len(records), limit, offset)
+	return records[start:end], nil
+}
+
+func (r *memoryGames) CountByPlayer(playerID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.byPlayer(playerID))), nil
+}
+
+type memorySessions struct {
+	mu       sync.RWMutex
+	sessions map[string]model.Session
+}
+
+func (r *memorySessions) Create(session *model.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[session.ID]; ok {
+		return errDuplicate
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memorySessions) GetByID(id string) (*model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *memorySessions) Revoke(id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session, ok := r.sessions[id]; ok {
+		session.RevokedAt = &at
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *memorySessions) RevokeByPlayer(playerID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, session := range r.sessions {
+		if session.PlayerID == playerID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			r.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (r *memorySessions) DeleteExpired(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+type memoryRatings struct {
+	mu      sync.RWMutex
+	history []model.RatingHistory
+	players *memoryPlayers // レーティングを書き換えるプレイヤー
+}
+
+func (r *memoryRatings) Save(playerIDs []string, compute RatingCompute) error {
+	// 読み込みから書き戻しまでプレイヤーをロックしておく
+	r.players.mu.Lock()
+	defer r.players.mu.Unlock()
+	players := make([]*model.Player, len(playerIDs))
+	for i, id := range playerIDs {
+		player, ok := r.players.players[id]
+		if !ok {
+			return ErrNotFound
+		}
+		players[i] = &player
+	}
+
+	history := compute(players)
+	if len(history) == 0 {
+		return nil
+	}
+	for _, p := range players {
+		stored := r.players.players[p.ID]
+		stored.Rating, stored.RatingRD, stored.Volatility, stored.RatedGames = p.Rating, p.RatingRD, p.Volatility, p.RatedGames
+		r.players.players[p.ID] = stored
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range history {
+		h.ID = uint(len(r.history) + 1)
+		if h.CreatedAt.IsZero() {
+			h.CreatedAt = time.Now()
+		}
+		r.history = append(r.history, *h)
+	}
+	return nil
+}
+
+func (r *memoryRatings) Leaderboard(limit, offset int) ([]*model.Player, error) {
+	r.players.mu.RLock()
+	players := []*model.Player{}
+	for _, player := range r.players.players {
+		if !player.IsBot && player.RatedGames > 0 {
+			player := player
+			players = append(players, &player)
+		}
+	}
+	r.players.mu.RUnlock()
+
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Rating != players[j].Rating {
+			return players[i].Rating > players[j].Rating
+		}
+		return players[i].ID < players[j].ID
+	})
+	start, end := pageRange(len(players), limit, offset)
+	return players[start:end], nil
+}
+
+func (r *memoryRatings) History(playerID string, limit, offset int) ([]*model.RatingHistory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	history := []*model.RatingHistory{}
+	for i := len(r.history) - 1; i >= 0; i-- {
+		if h := r.history[i]; h.PlayerID == playerID {
+			history = append(history, &h)
+		}
+	}
+	start, end := pageRange(len(history), limit, offset)
+	return history[start:end], nil
+}
+
+type memoryChats struct {
+	mu       sync.RWMutex
+	messages []model.ChatMessage
+}
+
+func (r *memoryChats) Create(msg *model.ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg.ID = uint(len(r.messages) + 1)
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	r.messages = append(r.messages, *msg)
+	return nil
+}
+
+func (r *memoryChats) ListRecent(roomID string, limit int) ([]*model.ChatMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	msgs := []*model.ChatMessage{}
+	for i := len(r.messages) - 1; i >= 0 && (limit < 0 || len(msgs) < limit); i-- {
+		if msg := r.messages[i]; msg.RoomID == roomID {
+			msgs = append(msgs, &msg)
+		}
+	}
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+	return msgs, nil
+}
@@ -0,0 +1,36 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/model"
+	"be-binareversi/repository"
+	"os"
+	"testing"
+)
+
+// docker compose up -d postgres
+// TEST_POSTGRES_DSN="host=localhost user=binareversi password=binareversi dbname=binareversi port=5432 sslmode=disable" go test -tags integration ./repository/
+func Test03_GormPostgresRepositories(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	database, err := db.Open(db.Config{Driver: db.DriverPostgres, DSN: dsn})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	// 前回の実行結果を消してから確認する
+	database.Exec("DELETE FROM game_records")
+	database.Where("1 = 1").Delete(&model.Session{})
+	database.Where("1 = 1").Delete(&model.RatingHistory{})
+	database.Where("1 = 1").Delete(&model.ChatMessage{})
+	database.Where("1 = 1").Delete(&model.Room{})
+	database.Where("1 = 1").Delete(&model.Player{})
+
+	runContract(t, repository.NewGorm(database))
+}
@@ -0,0 +1,139 @@
+// Package repository はプレイヤー・ルーム・対局記録の永続化を抽象化する
+package repository
+
+import (
+	"be-binareversi/model"
+	"errors"
+	"time"
+)
+
+// 対象のレコードが存在しない
+var ErrNotFound = errors.New("record not found")
+
+type PlayerRepository interface {
+	Create(player *model.Player) error
+	GetByID(id string) (*model.Player, error)
+	GetByUsername(username string) (*model.Player, error)
+	Update(player *model.Player) error
+	// 以下は指定したカラムだけを更新する（読み込んだ後に変わったレーティングなどを書き戻さない）
+	// 最終利用日時を更新
+	Touch(id string, at time.Time) error
+	// 表示名と最終利用日時を更新
+	Rename(id, name string, at time.Time) error
+	// ユーザー名・パスワードハッシュと最終利用日時を更新（ユーザー名が使われていればエラー）
+	SetCredentials(id, username, passwordHash string, at time.Time) error
+	Delete(id string) error
+	// before より前から使われていないゲストプレイヤーを削除（アカウント登録済みは対象外）
+	DeleteInactive(before time.Time) error
+}
+
+type RoomRepository interface {
+	Create(room *model.Room) error
+	GetByID(id string) (*model.Room, error)
+	GetByInviteCodeHash(hash string) (*model.Room, error)
+	GetByPlayerID(player1ID string) ([]*model.Room, error)
+	GetAll() ([]*model.Room, error)
+	// 公開ルームをプレイヤー名・レーティングと結合して1回の問い合わせで取得
+	ListPublic(query RoomQuery) ([]*RoomListing, error)
+	Update(room *model.Room) error
+	Delete(id string) error
+	// before より前に作成されたルームを削除
+	DeleteCreatedBefore(before time.Time) error
+}
+
+// ルーム一覧の並び順
+const (
+	RoomSortOldest     = "oldest"      // 作成日時の古い順（既定）
+	RoomSortNewest     = "newest"      // 作成日時の新しい順
+	RoomSortRatingDesc = "rating_desc" // ホストのレーティングの高い順
+	RoomSortRatingAsc  = "rating_asc"  // ホストのレーティングの低い順
+)
+
+// 公開ルーム一覧の検索条件
+type RoomQuery struct {
+	Status    string  // open / full（空なら両方）
+	Variant   string  // 空なら全て
+	MinRating float64 // ホストのレーティングの下限（0 なら無制限）
+	MaxRating float64 // ホストのレーティングの上限（0 なら無制限）
+	Sort      string  // RoomSort*（空なら RoomSortOldest）
+	After     *RoomCursor
+	Offset    int
+	Limit     int // 0 なら無制限
+}
+
+// 一覧上の位置（この位置より後ろのルームを返す）
+type RoomCursor struct {
+	CreatedAt time.Time `json:"t,omitempty"`
+	Rating    float64   `json:"r,omitempty"`
+	ID        string    `json:"id"`
+}
+
+// ホスト・参加者の情報を結合したルーム
+type RoomListing struct {
+	model.Room
+	Player1Name   string  `gorm:"column:player1_name"`
+	Player2Name   string  `gorm:"column:player2_name"`
+	Player1Rating float64 `gorm:"column:player1_rating"`
+}
+
+// このルームの一覧上の位置を返す
+func (l *RoomListing) Cursor() RoomCursor {
+	return RoomCursor{CreatedAt: l.CreatedAt, Rating: l.Player1Rating, ID: l.ID}
+}
+
+// 並び順が作成日時によるものかどうか
+func (q RoomQuery) byCreatedAt() bool {
+	return q.Sort != RoomSortRatingDesc && q.Sort != RoomSortRatingAsc
+}
+
+// 並び順が降順かどうか
+func (q RoomQuery) descending() bool {
+	return q.Sort == RoomSortNewest || q.Sort == RoomSortRatingDesc
+}
+
+type GameRepository interface {
+	Create(record *model.GameRecord) error
+	// プレイヤーの対局記録を新しい順に取得（limit が負なら全件）
+	ListByPlayer(playerID string, limit, offset int) ([]*model.GameRecord, error)
+	CountByPlayer(playerID string) (int64, error)
+}
+
+type SessionRepository interface {
+	Create(session *model.Session) error
+	GetByID(id string) (*model.Session, error)
+	// セッションを失効させる
+	Revoke(id string, at time.Time) error
+	// プレイヤーの有効なセッションをすべて失効させる
+	RevokeByPlayer(playerID string, at time.Time) error
+	// before より前に期限切れになったセッションを削除
+	DeleteExpired(before time.Time) error
+}
+
+// レーティングの再計算に使う関数
+// 読み込んだプレイヤー（指定した ID の順）のレーティングを書き換え、保存する履歴を返す（空なら何も保存しない）
+type RatingCompute func(players []*model.Player) []*model.RatingHistory
+
+type RatingRepository interface {
+	// プレイヤーの読み込みから更新・履歴の保存までを、同時に終わった対局の更新を失わないように行う
+	Save(playerIDs []string, compute RatingCompute) error
+	// レーティング順にプレイヤーを取得（AIと未対局のプレイヤーは除く。limit が負なら全件）
+	Leaderboard(limit, offset int) ([]*model.Player, error)
+	// プレイヤーのレーティング履歴を新しい順に取得（limit が負なら全件）
+	History(playerID string, limit, offset int) ([]*model.RatingHistory, error)
+}
+
+type ChatRepository interface {
+	Create(msg *model.ChatMessage) error
+	// ルームの最近の発言を古い順に取得（roomID が空ならロビー）
+	ListRecent(roomID string, limit int) ([]*model.ChatMessage, error)
+}
+
+// 各リポジトリをまとめたもの
+type Repositories struct {
+	Players  PlayerRepository
+	Rooms    RoomRepository
+	Games    GameRepository
+	Sessions SessionRepository
+	Ratings  RatingRepository
+	Chats    ChatRepository
+}
@@ -0,0 +1,309 @@
+package repository_test
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/model"
+	"be-binareversi/repository"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 実装によらず満たすべき振る舞いを確認する
+func runContract(t *testing.T, repos repository.Repositories) {
+	t.Run("Players", func(t *testing.T) {
+		username := "alice_01"
+		guest := &model.Player{ID: "p-guest", Name: "guest", LastUsedAt: time.Now().Add(-2 * time.Hour)}
+		member := &model.Player{ID: "p-member", Name: "member", Username: &username, LastUsedAt: time.Now().Add(-2 * time.Hour)}
+		for _, p := range []*model.Player{guest, member} {
+			if err := repos.Players.Create(p); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+
+		got, err := repos.Players.GetByID("p-guest")
+		if err != nil || got.Name != "guest" || got.Rating != 1500 {
+			t.Errorf("Unexpected player: %+v, %v", got, err)
+		}
+		if got, err := repos.Players.GetByUsername(username); err != nil || got.ID != "p-member" {
+			t.Errorf("GetByUsername failed: %+v, %v", got, err)
+		}
+		if _, err := repos.Players.GetByID("missing"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+
+		got.Name = "renamed"
+		repos.Players.Update(got)
+		if got, _ := repos.Players.GetByID("p-guest"); got.Name != "renamed" {
+			t.Errorf("Expected renamed player, got %q", got.Name)
+		}
+
+		// カラムを指定した更新は、読み込んだ後に変わった他のカラムを書き戻さない
+		stale, _ := repos.Players.GetByID("p-guest")
+		got.Rating = 1620
+		repos.Players.Update(got)
+		repos.Players.Touch(stale.ID, stale.LastUsedAt)
+		repos.Players.Rename(stale.ID, "renamed again", stale.LastUsedAt)
+		if got, _ := repos.Players.GetByID("p-guest"); got.Rating != 1620 || got.Name != "renamed again" {
+			t.Errorf("Expected rating to survive column updates, got %+v", got)
+		}
+		if err := repos.Players.SetCredentials("p-guest", username, "hash", stale.LastUsedAt); err == nil {
+			t.Error("Expected taken username to be rejected")
+		}
+		if got, _ := repos.Players.GetByID("p-guest"); got.Username != nil {
+			t.Errorf("Expected username to stay unset, got %q", *got.Username)
+		}
+
+		// アカウント登録済みのプレイヤーは残る
+		repos.Players.DeleteInactive(time.Now().Add(-time.Hour))
+		if _, err := repos.Players.GetByID("p-guest"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected inactive guest to be deleted, got %v", err)
+		}
+		if _, err := repos.Players.GetByID("p-member"); err != nil {
+			t.Errorf("Expected member to remain, got %v", err)
+		}
+	})
+
+	t.Run("Rooms", func(t *testing.T) {
+		old := &model.Room{ID: "r-old", Player1: "p1", CreatedAt: time.Now().Add(-2 * time.Hour)}
+		room := &model.Room{ID: "r-new", Player1: "p1", IsPrivate: true, InviteCodeHash: "hash"}
+		for _, r := range []*model.Room{old, room} {
+			if err := repos.Rooms.Create(r); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+
+		if got, err := repos.Rooms.GetByInviteCodeHash("hash"); err != nil || got.ID != "r-new" {
+			t.Errorf("GetByInviteCodeHash failed: %+v, %v", got, err)
+		}
+		if rooms, _ := repos.Rooms.GetByPlayerID("p1"); len(rooms) != 2 || rooms[0].ID != "r-old" {
+			t.Errorf("Expected 2 rooms in creation order, got %d", len(rooms))
+		}
+
+		p2 := "p2"
+		room.Player2, room.IsFull = &p2, true
+		repos.Rooms.Update(room)
+		if got, _ := repos.Rooms.GetByID("r-new"); !got.IsFull || got.Player2 == nil || *got.Player2 != "p2" {
+			t.Errorf("Expected updated room, got %+v", got)
+		}
+
+		repos.Rooms.DeleteCreatedBefore(time.Now().Add(-time.Hour))
+		if all, _ := repos.Rooms.GetAll(); len(all) != 1 {
+			t.Errorf("Expected 1 room after cleanup, got %d", len(all))
+		}
+		repos.Rooms.Delete("r-new")
+		if _, err := repos.Rooms.GetByID("r-new"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ListPublic", func(t *testing.T) {
+		now := time.Now()
+		for i, rating := range []float64{1400, 1600, 1800} {
+			id := fmt.Sprintf("host-%d", i)
+			repos.Players.Create(&model.Player{ID: id, Name: id, Rating: rating, LastUsedAt: now})
+		}
+		repos.Players.Create(&model.Player{ID: "guest-2", Name: "guest", LastUsedAt: now})
+		guest := "guest-2"
+		rooms := []*model.Room{
+			{ID: "l-0", Player1: "host-0", Variant: "standard", CreatedAt: now.Add(-3 * time.Minute)},
+			{ID: "l-1", Player1: "host-1", Variant: "blitz", CreatedAt: now.Add(-2 * time.Minute)},
+			{ID: "l-2", Player1: "host-2", Player2: &guest, IsFull: true, Variant: "standard", CreatedAt: now.Add(-time.Minute)},
+			{ID: "l-3", Player1: "host-2", Variant: "standard", IsPrivate: true, CreatedAt: now},
+		}
+		for _, r := range rooms {
+			if err := repos.Rooms.Create(r); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+		ids := func(listings []*repository.RoomListing) string {
+			var out []string
+			for _, l := range listings {
+				out = append(out, l.ID)
+			}
+			return strings.Join(out, ",")
+		}
+
+		all, err := repos.Rooms.ListPublic(repository.RoomQuery{Sort: repository.RoomSortNewest})
+		if err != nil || ids(all) != "l-2,l-1,l-0" {
+			t.Fatalf("Expected newest-first public rooms, got %q, %v", ids(all), err)
+		}
+		if all[0].Player1Name != "host-2" || all[0].Player2Name != "guest" || all[0].Player1Rating != 1800 {
+			t.Errorf("Expected joined player columns, got %+v", all[0])
+		}
+
+		page, _ := repos.Rooms.ListPublic(repository.RoomQuery{Limit: 2})
+		cursor := page[1].Cursor()
+		next, _ := repos.Rooms.ListPublic(repository.RoomQuery{After: &cursor})
+		if ids(page) != "l-0,l-1" || ids(next) != "l-2" {
+			t.Errorf("Unexpected cursor pages: %q then %q", ids(page), ids(next))
+		}
+
+		for _, c := range []struct {
+			query repository.RoomQuery
+			want  string
+		}{
+			{repository.RoomQuery{Status: "open"}, "l-0,l-1"},
+			{repository.RoomQuery{Status: "full"}, "l-2"},
+			{repository.RoomQuery{Variant: "blitz"}, "l-1"},
+			{repository.RoomQuery{MinRating: 1500, MaxRating: 1700}, "l-1"},
+			{repository.RoomQuery{Sort: repository.RoomSortRatingAsc}, "l-0,l-1,l-2"},
+			{repository.RoomQuery{Sort: repository.RoomSortRatingDesc, After: &repository.RoomCursor{Rating: 1600, ID: "l-1"}}, "l-0"},
+		} {
+			if got, _ := repos.Rooms.ListPublic(c.query); ids(got) != c.want {
+				t.Errorf("ListPublic(%+v) = %q, want %q", c.query, ids(got), c.want)
+			}
+		}
+	})
+
+	t.Run("Games", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			record := &model.GameRecord{RoomID: "r", BlackID: "a", WhiteID: "b", Winner: i % 2}
+			if err := repos.Games.Create(record); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+		repos.Games.Create(&model.GameRecord{RoomID: "r", BlackID: "c", WhiteID: "d"})
+
+		if n, _ := repos.Games.CountByPlayer("b"); n != 3 {
+			t.Errorf("Expected 3 games, got %d", n)
+		}
+		records, _ := repos.Games.ListByPlayer("a", 2, 0)
+		if len(records) != 2 || records[0].ID < records[1].ID {
+			t.Errorf("Expected 2 newest-first records, got %d", len(records))
+		}
+		if all, _ := repos.Games.ListByPlayer("a", -1, 1); len(all) != 2 {
+			t.Errorf("Expected 2 records with offset, got %d", len(all))
+		}
+		// 負の limit と offset は指定なしとみなす（プロフィールは全件を取得する）
+		if all, err := repos.Games.ListByPlayer("a", -1, -1); err != nil || len(all) != 3 {
+			t.Errorf("Expected all 3 records without limit and offset, got %d, %v", len(all), err)
+		}
+	})
+
+	t.Run("Sessions", func(t *testing.T) {
+		now := time.Now()
+		for _, s := range []*model.Session{
+			{ID: "s-1", PlayerID: "p1", ExpiresAt: now.Add(time.Hour)},
+			{ID: "s-2", PlayerID: "p1", ExpiresAt: now.Add(time.Hour)},
+			{ID: "s-old", PlayerID: "p2", ExpiresAt: now.Add(-time.Hour)},
+		} {
+			if err := repos.Sessions.Create(s); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+		if _, err := repos.Sessions.GetByID("missing"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+
+		repos.Sessions.Revoke("s-1", now)
+		if s, _ := repos.Sessions.GetByID("s-1"); s.RevokedAt == nil {
+			t.Error("Expected s-1 to be revoked")
+		}
+		if s, _ := repos.Sessions.GetByID("s-2"); s.RevokedAt != nil {
+			t.Error("Expected s-2 to stay valid")
+		}
+		repos.Sessions.RevokeByPlayer("p1", now)
+		if s, _ := repos.Sessions.GetByID("s-2"); s.RevokedAt == nil {
+			t.Error("Expected all of p1's sessions to be revoked")
+		}
+
+		repos.Sessions.DeleteExpired(now)
+		if _, err := repos.Sessions.GetByID("s-old"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected expired session to be deleted, got %v", err)
+		}
+		if _, err := repos.Sessions.GetByID("s-1"); err != nil {
+			t.Errorf("Expected unexpired session to remain, got %v", err)
+		}
+	})
+
+	t.Run("Ratings", func(t *testing.T) {
+		for _, p := range []*model.Player{
+			{ID: "r-a", Name: "a", LastUsedAt: time.Now()},
+			{ID: "r-b", Name: "b", LastUsedAt: time.Now()},
+			{ID: "r-bot", Name: "CPU", IsBot: true, LastUsedAt: time.Now()},
+		} {
+			repos.Players.Create(p)
+		}
+		win := func(winner, loser string) error {
+			return repos.Ratings.Save([]string{winner, loser}, func(players []*model.Player) []*model.RatingHistory {
+				var history []*model.RatingHistory
+				for i, p := range players {
+					delta := 10.0 - 20*float64(i)
+					p.Rating += delta
+					p.RatedGames++
+					history = append(history, &model.RatingHistory{PlayerID: p.ID, Rating: p.Rating, Delta: delta})
+				}
+				return history
+			})
+		}
+		if err := win("r-a", "r-b"); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := win("r-a", "r-bot"); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := win("r-a", "missing"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a missing player, got %v", err)
+		}
+		// 履歴を返さなければ何も書き換えない
+		repos.Ratings.Save([]string{"r-b"}, func(players []*model.Player) []*model.RatingHistory {
+			players[0].Rating = 0
+			return nil
+		})
+
+		if a, _ := repos.Players.GetByID("r-a"); a.Rating != 1520 || a.RatedGames != 2 {
+			t.Errorf("Expected r-a to gain twice, got %+v", a)
+		}
+		board, err := repos.Ratings.Leaderboard(-1, -1)
+		if err != nil || len(board) != 2 || board[0].ID != "r-a" || board[1].ID != "r-b" || board[1].Rating != 1490 {
+			t.Errorf("Expected r-a then r-b without the bot, got %+v, %v", board, err)
+		}
+		if page, _ := repos.Ratings.Leaderboard(1, 1); len(page) != 1 || page[0].ID != "r-b" {
+			t.Errorf("Expected r-b on the second page, got %+v", page)
+		}
+		history, err := repos.Ratings.History("r-a", -1, -1)
+		if err != nil || len(history) != 2 || history[0].Rating != 1520 || history[1].Rating != 1510 {
+			t.Errorf("Expected newest-first history, got %+v, %v", history, err)
+		}
+		if page, _ := repos.Ratings.History("r-a", 1, 1); len(page) != 1 || page[0].Rating != 1510 {
+			t.Errorf("Expected the older entry on the second page, got %+v", page)
+		}
+	})
+
+	t.Run("Chats", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			msg := &model.ChatMessage{RoomID: "c-room", Channel: "players", PlayerID: "p1", Text: fmt.Sprint(i)}
+			if err := repos.Chats.Create(msg); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+		repos.Chats.Create(&model.ChatMessage{Channel: "lobby", PlayerID: "p1", Text: "lobby"})
+
+		msgs, err := repos.Chats.ListRecent("c-room", 2)
+		if err != nil || len(msgs) != 2 || msgs[0].Text != "1" || msgs[1].Text != "2" {
+			t.Errorf("Expected the 2 latest messages oldest-first, got %+v, %v", msgs, err)
+		}
+		if lobby, _ := repos.Chats.ListRecent("", 10); len(lobby) != 1 || lobby[0].Text != "lobby" {
+			t.Errorf("Expected only the lobby message, got %+v", lobby)
+		}
+	})
+}
+
+func Test01_MemoryRepositories(t *testing.T) {
+	runContract(t, repository.NewMemory())
+}
+
+func Test02_GormSQLiteRepositories(t *testing.T) {
+	database, err := db.Open(db.Config{Driver: db.DriverSQLite, DSN: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	runContract(t, repository.NewGorm(database))
+}
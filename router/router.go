@@ -3,6 +3,7 @@ package router
 import (
 	"time"
 
+	"be-binareversi/auth"
 	"be-binareversi/handler"
 
 	"github.com/gin-contrib/cors"
@@ -12,13 +13,29 @@ import (
 func Setup(r *gin.Engine) {
 	r.Use(cors.New(cors.Config{
 		AllowAllOrigins: true,
-		AllowMethods:    []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:    []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:    []string{"Origin", "Content-Type", "Accept", "Authorization"},
 		ExposeHeaders:   []string{"Content-Length"},
 		MaxAge:          12 * time.Hour,
 	}))
 
 	r.POST("/api/register", handler.RegisterPlayer)
+	r.POST("/api/session/refresh", auth.RequireSession(), handler.RefreshSession)
+	r.DELETE("/api/session", auth.RequireSession(), handler.RevokeSession)
+	r.POST("/api/account/upgrade", auth.RequireSession(), handler.UpgradeAccount)
+	r.POST("/api/login", handler.Login)
+	r.POST("/api/logout", auth.RequireSession(), handler.RevokeSession)
+	r.GET("/api/leaderboard", handler.GetLeaderboard)
+	r.GET("/api/players/:id", handler.GetPlayerProfile)
+	r.PATCH("/api/players/:id", auth.RequireSession(), handler.UpdatePlayerProfile)
+	r.GET("/api/players/:id/games", handler.GetPlayerGames)
+	r.GET("/api/players/:id/ratings", handler.GetRatingHistory)
+	r.GET("/api/rooms", handler.ListRooms)
+	r.POST("/api/rooms", auth.RequireSession(), handler.CreateRoom)
+	r.GET("/api/rooms/:id", handler.GetRoom)
+	r.POST("/api/rooms/:id/join", auth.RequireSession(), handler.JoinRoom)
+	r.DELETE("/api/rooms/:id", auth.RequireSession(), handler.DeleteRoom)
 	r.GET("/ws/lobby", handler.LobbyWebSocket)
+	r.GET("/ws/game/:roomID", handler.GameWebSocket)
 	r.GET("/ws/game/:roomID/:playerID", handler.GameWebSocket)
 }
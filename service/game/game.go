@@ -0,0 +1,372 @@
+// Package game は1局分の対局ルール（着手・演算・パス・投了）を通信から切り離して扱う
+package game
+
+import (
+	"be-binareversi/libs/bitop"
+	"be-binareversi/libs/reversi"
+	"errors"
+	"fmt"
+)
+
+const (
+	MaxOperatorUses = 2 // 演算子ごとの使用上限
+	MaxPasses       = 3 // パスの上限
+)
+
+// 演算子
+const (
+	OperatorPlus = "+"
+	OperatorMul  = "*"
+)
+
+// 終了理由
+const (
+	ReasonNoMoves    = "no_moves"
+	ReasonDoublePass = "double_pass"
+	ReasonSurrender  = "surrender"
+	ReasonForfeit    = "forfeit" // 対局中に退出した
+)
+
+// 勝者なし（引き分け）
+const Draw = -1
+
+var (
+	ErrNotSeated       = errors.New("player is not seated")
+	ErrNotYourTurn     = errors.New("not your turn")
+	ErrRowOutOfBounds  = errors.New("row index out of bounds")
+	ErrTooManyPasses   = errors.New("You have exceeded the maximum number of passes (3).")
+	ErrUnknownOperator = errors.New("unknown operator")
+)
+
+// 演算子の使用上限を超えた場合のエラー
+type OperatorLimitError struct {
+	Operator string
+}
+
+func (e *OperatorLimitError) Error() string {
+	return fmt.Sprintf("Operator %s used too many times (max %d).", e.Operator, MaxOperatorUses)
+}
+
+// アクションの結果
+type Result struct {
+	PlayerID string
+	Action   string // move / operation / pass / surrender
+	Over     bool   // このアクションで対局が終わったか
+	Winner   int    // Over のときの勝者（Black=1, White=0, 引き分け=-1）
+	Reason   string // Over のときの終了理由
+}
+
+// プレイヤーごとの残り回数
+type Status struct {
+	RemainingPlus int `json:"remaining_plus"`
+	RemainingMul  int `json:"remaining_mul"`
+	RemainingPass int `json:"remaining_pass"`
+}
+
+// 局面（待ったで巻き戻すために保持する）
+type Snapshot struct {
+	board          [][]int
+	turn           int
+	turnCount      int
+	passCounts     map[string]int
+	operatorCounts map[string]map[string]int
+	lastPassPlayer string
+}
+
+// Match は1局分の対局ルールを扱う
+type Match interface {
+	// 盤面（読み取り用）
+	Game() *reversi.Game
+	// プレイヤーを着席させる（着席済みなら false）
+	Seat(playerID string, color int) bool
+	// 着席を取り消す（対局開始前の退出・キック用）
+	Unseat(playerID string)
+	// プレイヤーの色
+	Color(playerID string) (int, bool)
+	// 着席者と色の一覧（コピー）
+	Players() map[string]int
+	// 指定した色のプレイヤー（いなければ空文字）
+	PlayerOf(color int) string
+	// 対戦相手（いなければ空文字）
+	Opponent(playerID string) string
+
+	Move(playerID string, x, y int) (*Result, error)
+	Operate(playerID string, rowIndex, value int, operator string) (*Result, error)
+	Pass(playerID string) (*Result, error)
+	Surrender(playerID string) (*Result, error)
+	// 対局中の退出を負けとして扱う
+	Forfeit(playerID string) (*Result, error)
+
+	// 残り回数
+	Status(playerID string) Status
+	// 演算子の使用回数
+	OperatorUses(playerID, operator string) int
+
+	Snapshot() Snapshot
+	Restore(snap Snapshot)
+	// 色を入れ替えて初期局面に戻す（再戦用）
+	Rematch()
+	// 開始局面を差し替えて初期局面に戻す（対局の開始前・再戦時に使う）
+	Reset(setup reversi.Setup)
+}
+
+type match struct {
+	id             string
+	game           *reversi.Game
+	setup          reversi.Setup
+	colors         map[string]int
+	passCounts     map[string]int
+	lastPassPlayer string
+	operatorCounts map[string]map[string]int
+}
+
+// 新しい対局を作成する
+// @param roomID ルームID
+// @return Match 初期局面の対局
+func NewMatch(roomID string) Match {
+	m := &match{id: roomID, setup: reversi.StandardSetup(reversi.DefaultSize), colors: make(map[string]int)}
+	m.reset()
+	return m
+}
+
+func (m *match) reset() {
+	m.game = reversi.NewGameFrom(m.id, m.setup)
+	m.passCounts = make(map[string]int)
+	m.operatorCounts = make(map[string]map[string]int)
+	m.lastPassPlayer = ""
+}
+
+func (m *match) Game() *reversi.Game {
+	return m.game
+}
+
+func (m *match) Seat(playerID string, color int) bool {
+	if _, ok := m.colors[playerID]; ok {
+		return false
+	}
+	m.colors[playerID] = color
+	return true
+}
+
+func (m *match) Unseat(playerID string) {
+	delete(m.colors, playerID)
+}
+
+func (m *match) Color(playerID string) (int, bool) {
+	color, ok := m.colors[playerID]
+	return color, ok
+}
+
+func (m *match) Players() map[string]int {
+	players := make(map[string]int, len(m.colors))
+	for pid, color := range m.colors {
+		players[pid] = color
+	}
+	return players
+}
+
+func (m *match) PlayerOf(color int) string {
+	for pid, c := range m.colors {
+		if c == color {
+			return pid
+		}
+	}
+	return ""
+}
+
+func (m *match) Opponent(playerID string) string {
+	for pid := range m.colors {
+		if pid != playerID {
+			return pid
+		}
+	}
+	return ""
+}
+
+// 石を置く
+// @param playerID 着手したプレイヤー
+// @param x X座標
+// @param y Y座標
+// @return *Result 着手の結果（打てる場所がなくなれば Over）
+func (m *match) Move(playerID string, x, y int) (*Result, error) {
+	color, ok := m.colors[playerID]
+	if !ok {
+		return nil, ErrNotSeated
+	}
+	m.game.IncrementTurnCount()
+
+	if _, err := m.game.PlaceDisc(color, x, y); err != nil {
+		return nil, err
+	}
+
+	result := &Result{PlayerID: playerID, Action: "move"}
+	if m.game.IsGameOver() {
+		result.Over = true
+		result.Winner = m.game.GetWinner()
+		result.Reason = ReasonNoMoves
+	}
+	return result, nil
+}
+
+// 行に演算を適用する
+// @param playerID 演算したプレイヤー
+// @param rowIndex 対象の行
+// @param value 演算対象値
+// @param operator "+" または "*"
+// @return *Result 演算の結果
+func (m *match) Operate(playerID string, rowIndex, value int, operator string) (*Result, error) {
+	color, ok := m.colors[playerID]
+	if !ok {
+		return nil, ErrNotSeated
+	}
+	if color != m.game.GetTurn() {
+		return nil, ErrNotYourTurn
+	}
+	if operator != OperatorPlus && operator != OperatorMul {
+		return nil, ErrUnknownOperator
+	}
+	m.game.IncrementTurnCount()
+
+	if m.operatorCounts[playerID] == nil {
+		m.operatorCounts[playerID] = map[string]int{OperatorPlus: 0, OperatorMul: 0}
+	}
+	if m.operatorCounts[playerID][operator] >= MaxOperatorUses {
+		return nil, &OperatorLimitError{Operator: operator}
+	}
+	m.operatorCounts[playerID][operator]++
+
+	if rowIndex < 0 || rowIndex >= m.game.Size {
+		return nil, ErrRowOutOfBounds
+	}
+
+	// 対象の行を取得し演算
+	board := m.game.GetBoard()
+	newRow, err := bitop.ApplyBitOperation(board[rowIndex], value, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	// 盤面の更新
+	board[rowIndex] = newRow
+	m.game.SetBoard(board)
+	m.game.PassTurn()
+
+	return &Result{PlayerID: playerID, Action: "operation"}, nil
+}
+
+// パスする
+// @param playerID パスしたプレイヤー
+// @return *Result パスの結果（2人連続のパスなら Over）
+func (m *match) Pass(playerID string) (*Result, error) {
+	color, ok := m.colors[playerID]
+	if !ok {
+		return nil, ErrNotSeated
+	}
+	if color != m.game.GetTurn() {
+		return nil, ErrNotYourTurn
+	}
+	m.passCounts[playerID]++
+
+	if m.passCounts[playerID] > MaxPasses {
+		m.passCounts[playerID] = MaxPasses // 上限固定
+		return nil, ErrTooManyPasses
+	}
+
+	result := &Result{PlayerID: playerID, Action: "pass"}
+
+	// 連続パス判定
+	if m.lastPassPlayer != "" && m.lastPassPlayer != playerID {
+		// 2人連続でパスされた → 勝者判定
+		result.Over = true
+		result.Winner = m.game.GetWinner()
+		result.Reason = ReasonDoublePass
+		return result, nil
+	}
+
+	m.game.IncrementTurnCount()
+	m.game.PassTurn()
+	m.lastPassPlayer = playerID
+	return result, nil
+}
+
+// 投了する
+// @param playerID 投了したプレイヤー（相手の勝ち）
+func (m *match) Surrender(playerID string) (*Result, error) {
+	color, ok := m.colors[playerID]
+	if !ok {
+		return nil, ErrNotSeated
+	}
+	return &Result{
+		PlayerID: playerID,
+		Action:   "surrender",
+		Over:     true,
+		Winner:   1 - color,
+		Reason:   ReasonSurrender,
+	}, nil
+}
+
+func (m *match) Forfeit(playerID string) (*Result, error) {
+	result, err := m.Surrender(playerID)
+	if err != nil {
+		return nil, err
+	}
+	result.Action = "forfeit"
+	result.Reason = ReasonForfeit
+	return result, nil
+}
+
+func (m *match) Status(playerID string) Status {
+	return Status{
+		RemainingPlus: MaxOperatorUses - m.operatorCounts[playerID][OperatorPlus],
+		RemainingMul:  MaxOperatorUses - m.operatorCounts[playerID][OperatorMul],
+		RemainingPass: MaxPasses - m.passCounts[playerID],
+	}
+}
+
+func (m *match) OperatorUses(playerID, operator string) int {
+	return m.operatorCounts[playerID][operator]
+}
+
+// 現在の局面を保存する
+func (m *match) Snapshot() Snapshot {
+	snap := Snapshot{
+		board:          m.game.GetBoard(),
+		turn:           m.game.GetTurn(),
+		turnCount:      m.game.GetTurnCount(),
+		passCounts:     make(map[string]int),
+		operatorCounts: make(map[string]map[string]int),
+		lastPassPlayer: m.lastPassPlayer,
+	}
+	for pid, n := range m.passCounts {
+		snap.passCounts[pid] = n
+	}
+	for pid, counts := range m.operatorCounts {
+		snap.operatorCounts[pid] = map[string]int{}
+		for op, n := range counts {
+			snap.operatorCounts[pid][op] = n
+		}
+	}
+	return snap
+}
+
+// 保存した局面に戻す
+func (m *match) Restore(snap Snapshot) {
+	m.game.SetBoard(snap.board)
+	m.game.Turn = snap.turn
+	m.game.TurnCount = snap.turnCount
+	m.passCounts = snap.passCounts
+	m.operatorCounts = snap.operatorCounts
+	m.lastPassPlayer = snap.lastPassPlayer
+}
+
+func (m *match) Rematch() {
+	for pid, color := range m.colors {
+		m.colors[pid] = 1 - color
+	}
+	m.reset()
+}
+
+func (m *match) Reset(setup reversi.Setup) {
+	m.setup = setup
+	m.reset()
+}
@@ -0,0 +1,180 @@
+package game
+
+import (
+	"be-binareversi/libs/reversi"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newSeatedMatch() Match {
+	m := NewMatch("room")
+	m.Seat("black", reversi.Black)
+	m.Seat("white", reversi.White)
+	return m
+}
+
+func Test01_SeatOnce(t *testing.T) {
+	m := newSeatedMatch()
+	if m.Seat("black", reversi.White) {
+		t.Error("Expected second seat to be rejected")
+	}
+	if m.PlayerOf(reversi.White) != "white" || m.Opponent("black") != "white" {
+		t.Errorf("Unexpected seating: %v", m.Players())
+	}
+}
+
+func Test02_MoveAndTurn(t *testing.T) {
+	m := newSeatedMatch()
+	result, err := m.Move("black", 2, 3)
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if result.Over || result.Action != "move" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if m.Game().GetTurn() != reversi.White {
+		t.Error("Expected White's turn after Black's move")
+	}
+	if _, err := m.Move("nobody", 0, 0); !errors.Is(err, ErrNotSeated) {
+		t.Errorf("Expected ErrNotSeated, got %v", err)
+	}
+}
+
+func Test03_OperatorLimit(t *testing.T) {
+	m := newSeatedMatch()
+	for i := 0; i < MaxOperatorUses; i++ {
+		if _, err := m.Operate("black", 0, 1, OperatorPlus); err != nil {
+			t.Fatalf("Operate %d failed: %v", i, err)
+		}
+		if _, err := m.Operate("white", 0, 1, OperatorMul); err != nil {
+			t.Fatalf("Operate %d failed: %v", i, err)
+		}
+	}
+	_, err := m.Operate("black", 0, 1, OperatorPlus)
+	var limitErr *OperatorLimitError
+	if !errors.As(err, &limitErr) || limitErr.Operator != OperatorPlus {
+		t.Errorf("Expected OperatorLimitError, got %v", err)
+	}
+	if got := m.Status("black").RemainingPlus; got != 0 {
+		t.Errorf("Expected 0 remaining plus, got %d", got)
+	}
+	if _, err := m.Operate("black", 0, 1, "-"); !errors.Is(err, ErrUnknownOperator) {
+		t.Errorf("Expected ErrUnknownOperator, got %v", err)
+	}
+}
+
+func Test04_DoublePassEndsGame(t *testing.T) {
+	m := newSeatedMatch()
+	if result, err := m.Pass("black"); err != nil || result.Over {
+		t.Fatalf("Unexpected first pass: %+v, %v", result, err)
+	}
+	result, err := m.Pass("white")
+	if err != nil {
+		t.Fatalf("Pass failed: %v", err)
+	}
+	if !result.Over || result.Reason != ReasonDoublePass || result.Winner != Draw {
+		t.Errorf("Expected drawn double pass, got %+v", result)
+	}
+}
+
+func Test05_PassLimit(t *testing.T) {
+	m := newSeatedMatch()
+	for i := 0; i < MaxPasses; i++ {
+		if _, err := m.Pass("black"); err != nil {
+			t.Fatalf("Pass %d failed: %v", i, err)
+		}
+		// 連続パスにならないよう白は演算で手番を返す
+		m.Operate("white", 0, 1, []string{OperatorPlus, OperatorMul}[i%2])
+	}
+	if _, err := m.Pass("black"); !errors.Is(err, ErrTooManyPasses) {
+		t.Errorf("Expected ErrTooManyPasses, got %v", err)
+	}
+}
+
+func Test06_SurrenderWinner(t *testing.T) {
+	m := newSeatedMatch()
+	result, _ := m.Surrender("white")
+	if !result.Over || result.Winner != reversi.Black || result.Reason != ReasonSurrender {
+		t.Errorf("Expected Black to win by surrender, got %+v", result)
+	}
+}
+
+func Test07_SnapshotRestore(t *testing.T) {
+	m := newSeatedMatch()
+	snap := m.Snapshot()
+	m.Move("black", 2, 3)
+	m.Operate("white", 0, 1, OperatorMul)
+	m.Restore(snap)
+
+	if m.Game().GetTurn() != reversi.Black || !reflect.DeepEqual(m.Game().GetBoard(), reversi.NewGame("room").GetBoard()) {
+		t.Error("Expected initial position after restore")
+	}
+	if m.OperatorUses("white", OperatorMul) != 0 {
+		t.Error("Expected operator count to be restored")
+	}
+}
+
+func Test08_RematchSwapsColors(t *testing.T) {
+	m := newSeatedMatch()
+	m.Move("black", 2, 3)
+	m.Rematch()
+	if c, _ := m.Color("black"); c != reversi.White {
+		t.Errorf("Expected colors to swap, got %d", c)
+	}
+	if m.Game().GetTurnCount() != 1 {
+		t.Error("Expected fresh game after rematch")
+	}
+}
+
+func Test09_UnseatAndForfeit(t *testing.T) {
+	m := newSeatedMatch()
+	result, err := m.Forfeit("white")
+	if err != nil || result.Winner != reversi.Black || result.Reason != ReasonForfeit {
+		t.Errorf("Expected black to win by forfeit, got %+v, %v", result, err)
+	}
+
+	m.Unseat("white")
+	if _, ok := m.Color("white"); ok || m.Opponent("black") != "" {
+		t.Error("Expected white to be unseated")
+	}
+	if !m.Seat("carol", reversi.White) {
+		t.Error("Expected the empty seat to be available")
+	}
+	if _, err := m.Forfeit("white"); !errors.Is(err, ErrNotSeated) {
+		t.Errorf("Expected ErrNotSeated, got %v", err)
+	}
+}
+
+func Test10_ResetToSetup(t *testing.T) {
+	m := newSeatedMatch()
+	setup, _ := reversi.HandicapSetup(reversi.DefaultSize, reversi.White, 1)
+	m.Move("black", 2, 3)
+
+	m.Reset(setup)
+	if !reflect.DeepEqual(m.Game().GetBoard(), setup.Board) || m.Game().GetTurnCount() != 1 {
+		t.Fatal("Expected reset to the given setup")
+	}
+	// 再戦でも同じ開始局面から始める
+	m.Rematch()
+	if !reflect.DeepEqual(m.Game().GetBoard(), setup.Board) {
+		t.Error("Expected rematch to keep the setup")
+	}
+	if color, _ := m.Color("black"); color != reversi.White {
+		t.Errorf("Expected colors to swap on rematch, got %d", color)
+	}
+}
+
+func Test11_OutOfTurnRejected(t *testing.T) {
+	m := newSeatedMatch()
+	if _, err := m.Operate("white", 0, 1, OperatorPlus); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Expected ErrNotYourTurn for an operation, got %v", err)
+	}
+	if _, err := m.Pass("white"); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Expected ErrNotYourTurn for a pass, got %v", err)
+	}
+	// 手番を取られず、回数も消費しない
+	if m.Game().GetTurn() != reversi.Black || m.Status("white").RemainingPlus != MaxOperatorUses {
+		t.Errorf("Expected Black to move with White's operators intact, got turn %d, %+v", m.Game().GetTurn(), m.Status("white"))
+	}
+}
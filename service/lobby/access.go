@@ -0,0 +1,100 @@
+package lobby
+
+import (
+	"be-binareversi/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 招待コードに使う文字（紛らわしい 0/O, 1/I を除く）
+const inviteCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const inviteCodeLength = 6
+
+var (
+	ErrRoomAccessDenied = errors.New("invite code or password required")
+	ErrRoomReserved     = errors.New("room is reserved for another player")
+)
+
+// 招待コードを生成する
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := make([]byte, inviteCodeLength)
+	for i, b := range buf {
+		code[i] = inviteCodeChars[int(b)%len(inviteCodeChars)]
+	}
+	return string(code), nil
+}
+
+// 招待コードを検索用のハッシュに変換する
+func hashInviteCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// 非公開設定をルームに設定する
+// @param room 設定先のルーム
+// @param opts 作成時の指定
+// @return string 発行した招待コード（非公開でなければ空）
+// @return error 設定に失敗した場合はエラー
+func applyRoomAccess(room *model.Room, opts RoomOptions) (string, error) {
+	if reserved := opts.ReservedFor; reserved != "" {
+		room.ReservedFor = &reserved
+	}
+	if !opts.Private {
+		return "", nil
+	}
+
+	room.IsPrivate = true
+	code, err := generateInviteCode()
+	if err != nil {
+		return "", err
+	}
+	room.InviteCodeHash = hashInviteCode(code)
+
+	if password := opts.Password; password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", err
+		}
+		room.PasswordHash = string(hash)
+	}
+	return code, nil
+}
+
+// 着席していないプレイヤーがルームを観戦できるか
+// @param room 観戦するルーム
+// @return bool 観戦を許可した公開ルームなら true
+func CanSpectate(room *model.Room) bool {
+	return room.AllowSpectators && !room.IsPrivate
+}
+
+// プレイヤーがルームに参加できるかを検証する
+// @param room 参加先のルーム
+// @param playerID 参加するプレイヤー
+// @param inviteCode 提示された招待コード
+// @param password 提示されたパスワード
+// @return error 参加できなければエラー
+func checkRoomAccess(room *model.Room, playerID, inviteCode, password string) error {
+	if room.ReservedFor != nil && *room.ReservedFor != playerID {
+		return ErrRoomReserved
+	}
+	if !room.IsPrivate {
+		return nil
+	}
+	if inviteCode != "" && hashInviteCode(inviteCode) == room.InviteCodeHash {
+		return nil
+	}
+	if password != "" && room.PasswordHash != "" &&
+		bcrypt.CompareHashAndPassword([]byte(room.PasswordHash), []byte(password)) == nil {
+		return nil
+	}
+	return ErrRoomAccessDenied
+}
@@ -0,0 +1,33 @@
+package lobby
+
+import (
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidBoardSize = errors.New("invalid board size")
+
+// ルームの盤面の大きさを設定する
+// @param room 設定先のルーム
+// @param size 作成時の指定（0 なら 8）
+// @return error 偶数でない、または範囲外であればエラー
+func applyBoardSize(room *model.Room, size int) error {
+	if size == 0 {
+		size = reversi.DefaultSize
+	}
+	if err := reversi.ValidSize(size); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBoardSize, err)
+	}
+	room.BoardSize = size
+	return nil
+}
+
+// ルームの盤面の大きさ（未設定なら 8）
+func BoardSizeOf(room *model.Room) int {
+	if room.BoardSize == 0 {
+		return reversi.DefaultSize
+	}
+	return room.BoardSize
+}
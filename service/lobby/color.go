@@ -0,0 +1,32 @@
+package lobby
+
+import (
+	"be-binareversi/model"
+	"errors"
+)
+
+// 先手（Black）の決め方
+const (
+	ColorModeBlack     = "black"     // 作成者（Player1）が常に先手
+	ColorModeWhite     = "white"     // 作成者が常に後手
+	ColorModeRandom    = "random"    // 対局開始のたびに抽選する
+	ColorModeAlternate = "alternate" // 作成者が先手で始め、再戦のたびに入れ替える
+)
+
+var ErrUnknownColorMode = errors.New("unknown color mode")
+
+// ルームの先手の決め方を設定する
+// @param room 設定先のルーム
+// @param mode 作成時の指定（空なら random）
+// @return error 不正な指定であればエラー
+func applyColorMode(room *model.Room, mode string) error {
+	switch mode {
+	case "":
+		room.ColorMode = ColorModeRandom
+	case ColorModeBlack, ColorModeWhite, ColorModeRandom, ColorModeAlternate:
+		room.ColorMode = mode
+	default:
+		return ErrUnknownColorMode
+	}
+	return nil
+}
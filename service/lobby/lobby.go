@@ -0,0 +1,699 @@
+// Package lobby はルームの一覧・作成・参加・削除を通信手段から切り離して扱う
+package lobby
+
+import (
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	"be-binareversi/repository"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPlayer  = errors.New("invalid playerID")
+	ErrRoomNotFound   = errors.New("room not found")
+	ErrRoomFull       = errors.New("room not found or already full")
+	ErrNotRoomHost    = errors.New("only the host can do this")
+	ErrUnknownVariant = errors.New("unknown variant")
+	ErrInvalidControl = errors.New("invalid time control")
+	ErrRoomInProgress = errors.New("room already has two players")
+	ErrCreateRoom     = errors.New("failed to create room")
+	ErrInvalidSort    = errors.New("unknown sort key")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrNotInRoom      = errors.New("you are not in this room")
+	ErrNoOpponent     = errors.New("room has no opponent")
+	ErrGameStarted    = errors.New("game already started")
+)
+
+// ロビーに配信するイベントの種類
+const (
+	EventRoomCreated = "room_created"
+	EventRoomUpdated = "room_updated"
+	EventRoomDeleted = "room_deleted"
+	EventRoomStatus  = "room_status"
+)
+
+// 対局中の石数
+type Score struct {
+	Black int `json:"black"`
+	White int `json:"white"`
+}
+
+// レスポンス用構造体（ID）
+type RoomResponse struct {
+	ID        string    `json:"id"`
+	Player1   string    `json:"player1"`           // id
+	Player2   string    `json:"player2,omitempty"` // id
+	HostID    string    `json:"hostID"`
+	IsFull    bool      `json:"isFull"`
+	CreatedAt time.Time `json:"createdAt"`
+	Status    string    `json:"status"`
+	Score     *Score    `json:"score,omitempty"` // 対局が始まっていれば設定
+
+	HostRating float64 `json:"hostRating,omitempty"` // 一覧でのみ設定
+
+	Variant          string `json:"variant,omitempty"`
+	TimeControl      string `json:"timeControl,omitempty"`
+	InitialSeconds   int    `json:"initialSeconds,omitempty"`
+	IncrementSeconds int    `json:"incrementSeconds,omitempty"`
+	MoveSeconds      int    `json:"moveSeconds,omitempty"`
+	ColorMode        string `json:"colorMode,omitempty"`
+	BoardSize        int    `json:"boardSize"`
+	Opening          string `json:"opening,omitempty"`
+	Handicap         int    `json:"handicap,omitempty"`
+	Position         string `json:"position,omitempty"`
+	AllowSpectators  bool   `json:"allowSpectators"`
+
+	IsPrivate  bool   `json:"isPrivate,omitempty"`
+	Reserved   bool   `json:"reserved,omitempty"`
+	InviteCode string `json:"inviteCode,omitempty"` // 作成者にのみ返す
+}
+
+// ルーム作成時の指定
+type RoomOptions struct {
+	Variant          string `json:"variant"`
+	TimeControl      string `json:"timeControl"`
+	InitialSeconds   int    `json:"initialSeconds"`
+	IncrementSeconds int    `json:"incrementSeconds"`
+	MoveSeconds      int    `json:"moveSeconds"`
+	ColorMode        string `json:"colorMode"` // 空なら random
+	BoardSize        int    `json:"boardSize"` // 盤面の一辺（0 なら 8）
+	Opening          string `json:"opening"`   // 空なら standard
+	Handicap         int    `json:"handicap"`  // handicap の角の数（1〜4）
+	Position         string `json:"position"`  // custom の局面文字列
+	AllowSpectators  bool   `json:"allowSpectators"`
+	Private          bool   `json:"private"`
+	Password         string `json:"password"`
+	ReservedFor      string `json:"reservedFor"`
+}
+
+// ルーム参加時の指定
+type JoinOptions struct {
+	InviteCode string `json:"inviteCode"`
+	Password   string `json:"password"`
+}
+
+// ルーム一覧の絞り込み条件
+type RoomFilter struct {
+	Status    string // open / full（空なら両方）
+	Variant   string
+	MinRating float64 // ホストのレーティングの範囲（0 なら無制限）
+	MaxRating float64
+	Sort      string // oldest / newest / rating_desc / rating_asc（空なら oldest）
+	Cursor    string // 前のページの NextCursor（空なら先頭から）
+	Limit     int    // 0 なら無制限
+	Offset    int
+}
+
+// ルーム一覧の1ページ
+type RoomPage struct {
+	Rooms      []*RoomResponse `json:"rooms"`
+	NextCursor string          `json:"nextCursor,omitempty"` // 続きがあれば設定
+}
+
+// Service はロビーの業務ロジック
+type Service interface {
+	// 公開ルームの一覧
+	ListRooms(filter RoomFilter) (*RoomPage, error)
+	// ルームを1件取得（非公開ルームは参加者にのみ返す）
+	GetRoom(playerID, roomID string) (*RoomResponse, error)
+	// ルームを作成（Player1 = playerID）
+	CreateRoom(playerID string, opts RoomOptions) (*RoomResponse, error)
+	// ルームに参加（Player2 = playerID。roomID が空なら招待コードから引く）
+	JoinRoom(playerID, roomID string, opts JoinOptions) (*RoomResponse, error)
+	// ルームを削除（ホストのみ）
+	DeleteRoom(playerID, roomID string) error
+	// 対局開始前の対戦相手を退出させる（ホストのみ）。退出させたプレイヤーを返す
+	KickPlayer(hostID, roomID string) (string, error)
+	// ホストを対戦相手に譲る
+	TransferHost(hostID, roomID string) (*RoomResponse, error)
+	// 対局開始前のルームから退出する（最後の1人ならルームを閉じて true を返す）
+	LeaveRoom(playerID, roomID string) (bool, error)
+	// 自動マッチングの結果から満室のルームを作成
+	CreateMatchRoom(blackID, whiteID, variant string) (*model.Room, error)
+	// ルームを取得（対局の開始時などに使う。公開範囲は確認しない）
+	Room(roomID string) (*model.Room, error)
+	// ルームを閉じる（対局からの退出など）
+	CloseRoom(roomID string) error
+	// before より前に作成されたルームを削除
+	ExpireRooms(before time.Time) (int, error)
+	// 対局の進行状況を記録し、ロビーに通知
+	ReportStatus(roomID, status string, score Score) error
+	// 対局中のまま対局が失われたルーム（再起動後など）を開始前に戻す
+	RecoverRoom(roomID string) (*model.Room, error)
+}
+
+type service struct {
+	players repository.PlayerRepository
+	rooms   *Registry
+	notify  Notifier
+
+	scoreMu sync.RWMutex
+	scores  map[string]Score // 対局中・終了したルームの石数（永続化しない）
+}
+
+// ロビーの Service を作成する
+// @param players プレイヤーの永続化先
+// @param rooms ルームのキャッシュと永続化先
+// @param notify ロビー購読者への配信先
+// @return Service
+func New(players repository.PlayerRepository, rooms *Registry, notify Notifier) Service {
+	return &service{
+		players: players,
+		rooms:   rooms,
+		notify:  notify,
+		scores:  make(map[string]Score),
+	}
+}
+
+// ルームの各種設定をレスポンスに反映する
+func (resp *RoomResponse) setSettings(room *model.Room) {
+	resp.HostID = hostOf(room)
+	resp.Status = room.Status
+	resp.Variant = room.Variant
+	resp.IsPrivate = room.IsPrivate
+	resp.Reserved = room.ReservedFor != nil
+	resp.TimeControl = room.TimeControl
+	resp.InitialSeconds = room.InitialSeconds
+	resp.IncrementSeconds = room.IncrementSeconds
+	resp.MoveSeconds = room.MoveSeconds
+	resp.ColorMode = room.ColorMode
+	resp.BoardSize = BoardSizeOf(room)
+	resp.Opening = room.Opening
+	resp.Handicap = room.Handicap
+	resp.Position = room.Position
+	resp.AllowSpectators = room.AllowSpectators
+}
+
+// プレイヤー名を引く（見つからなければ空文字）
+func (s *service) playerName(playerID string) string {
+	if player, err := s.players.GetByID(playerID); err == nil && player != nil {
+		return player.Name
+	}
+	return ""
+}
+
+// ルームをレスポンス形式に変換する
+func (s *service) toResponse(room *model.Room) *RoomResponse {
+	resp := &RoomResponse{
+		ID:        room.ID,
+		Player1:   s.playerName(room.Player1),
+		IsFull:    room.IsFull,
+		CreatedAt: room.CreatedAt,
+	}
+	if room.Player2 != nil {
+		resp.Player2 = s.playerName(*room.Player2)
+	}
+	resp.setSettings(room)
+	s.scoreMu.RLock()
+	if score, ok := s.scores[room.ID]; ok {
+		resp.Score = &score
+	}
+	s.scoreMu.RUnlock()
+	return resp
+}
+
+// 公開ルームを1ページ分取得する（プレイヤー名は同じ問い合わせで結合する）
+// @param filter 絞り込み・並び順・ページの指定
+// @return *RoomPage ルームと次のページのカーソル
+func (s *service) ListRooms(filter RoomFilter) (*RoomPage, error) {
+	switch filter.Sort {
+	case "", repository.RoomSortOldest, repository.RoomSortNewest, repository.RoomSortRatingDesc, repository.RoomSortRatingAsc:
+	default:
+		return nil, ErrInvalidSort
+	}
+	query := repository.RoomQuery{
+		Status:    filter.Status,
+		Variant:   filter.Variant,
+		MinRating: filter.MinRating,
+		MaxRating: filter.MaxRating,
+		Sort:      filter.Sort,
+		Offset:    filter.Offset,
+	}
+	if filter.Cursor != "" {
+		after, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		query.After = after
+	}
+	// 続きがあるかを知るために1件多く取得する
+	if filter.Limit > 0 {
+		query.Limit = filter.Limit + 1
+	}
+
+	listings, err := s.rooms.ListPublic(query)
+	if err != nil {
+		return nil, err
+	}
+	page := &RoomPage{Rooms: []*RoomResponse{}}
+	if filter.Limit > 0 && len(listings) > filter.Limit {
+		listings = listings[:filter.Limit]
+		page.NextCursor = encodeCursor(listings[len(listings)-1].Cursor())
+	}
+	for _, l := range listings {
+		page.Rooms = append(page.Rooms, s.listingResponse(l))
+	}
+	return page, nil
+}
+
+// 一覧の行をレスポンス形式に変換する
+func (s *service) listingResponse(l *repository.RoomListing) *RoomResponse {
+	resp := &RoomResponse{
+		ID:         l.ID,
+		Player1:    l.Player1Name,
+		Player2:    l.Player2Name,
+		IsFull:     l.IsFull,
+		CreatedAt:  l.CreatedAt,
+		HostRating: l.Player1Rating,
+	}
+	resp.setSettings(&l.Room)
+	s.scoreMu.RLock()
+	if score, ok := s.scores[l.ID]; ok {
+		resp.Score = &score
+	}
+	s.scoreMu.RUnlock()
+	return resp
+}
+
+// 一覧上の位置を URL に載せられる文字列にする
+func encodeCursor(c repository.RoomCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// encodeCursor の逆変換
+func decodeCursor(s string) (*repository.RoomCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c repository.RoomCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func (s *service) GetRoom(playerID, roomID string) (*RoomResponse, error) {
+	room, err := s.rooms.Get(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.IsPrivate && room.Player1 != playerID && (room.Player2 == nil || *room.Player2 != playerID) {
+		return nil, ErrRoomNotFound
+	}
+	return s.toResponse(room), nil
+}
+
+// ルームを作成し、公開ルームであればロビーに通知する
+// @param playerID 作成者（Player1）
+// @param opts 作成時の指定
+// @return *RoomResponse 作成したルーム（非公開なら招待コード付き）
+func (s *service) CreateRoom(playerID string, opts RoomOptions) (*RoomResponse, error) {
+	player, err := s.players.GetByID(playerID)
+	if err != nil || player == nil {
+		return nil, ErrInvalidPlayer
+	}
+
+	room := &model.Room{ID: uuid.New().String(), Player1: playerID, Host: playerID, IsFull: false, Status: StatusWaiting}
+	if err := applyTimeControl(room, opts); err != nil {
+		return nil, err
+	}
+	if err := applyColorMode(room, opts.ColorMode); err != nil {
+		return nil, err
+	}
+	if err := applyBoardSize(room, opts.BoardSize); err != nil {
+		return nil, err
+	}
+	if err := applyOpening(room, opts); err != nil {
+		return nil, err
+	}
+	room.AllowSpectators = opts.AllowSpectators
+	inviteCode, err := applyRoomAccess(room, opts)
+	if err != nil {
+		return nil, ErrCreateRoom
+	}
+
+	if err := s.rooms.Create(room); err != nil {
+		return nil, ErrCreateRoom
+	}
+
+	resp := &RoomResponse{
+		ID:        room.ID,
+		Player1:   player.Name,
+		Player2:   "",
+		IsFull:    false,
+		CreatedAt: room.CreatedAt,
+	}
+	resp.setSettings(room)
+	if room.IsPrivate {
+		// 非公開ルームは一覧に流さず、作成者にだけ招待コードを返す
+		resp.InviteCode = inviteCode
+		return resp, nil
+	}
+	s.notify.Broadcast(map[string]interface{}{"type": EventRoomCreated, "room": resp})
+	return resp, nil
+}
+
+// ルームに参加し、公開ルームであればロビーに通知する
+// @param playerID 参加するプレイヤー（Player2）
+// @param roomID 参加先（空なら招待コードから引く）
+// @param opts 招待コード・パスワード
+// @return *RoomResponse 参加後のルーム
+func (s *service) JoinRoom(playerID, roomID string, opts JoinOptions) (*RoomResponse, error) {
+	player, err := s.players.GetByID(playerID)
+	if err != nil || player == nil {
+		return nil, ErrInvalidPlayer
+	}
+
+	// 招待コードのみ指定された場合はコードからルームを引く
+	if roomID == "" && opts.InviteCode != "" {
+		if found, err := s.rooms.GetByInviteCodeHash(hashInviteCode(opts.InviteCode)); err == nil {
+			roomID = found.ID
+		}
+	}
+
+	room, err := s.rooms.Update(roomID, func(room *model.Room) error {
+		if room.Status != StatusWaiting {
+			return ErrRoomFull
+		}
+		if err := checkRoomAccess(room, playerID, opts.InviteCode, opts.Password); err != nil {
+			return err
+		}
+		room.Player2 = &playerID
+		room.IsFull = true
+		room.Status = StatusReady
+		return nil
+	})
+	if errors.Is(err, ErrRoomNotFound) {
+		return nil, ErrRoomFull
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	player1Name := s.playerName(room.Player1)
+	if player1Name == "" {
+		player1Name = "unknown"
+	}
+
+	resp := &RoomResponse{
+		ID:        room.ID,
+		Player1:   player1Name,
+		Player2:   player.Name,
+		IsFull:    true,
+		CreatedAt: room.CreatedAt,
+	}
+	resp.setSettings(room)
+	if !room.IsPrivate {
+		s.notify.Broadcast(map[string]interface{}{"type": EventRoomUpdated, "room": resp})
+	}
+	return resp, nil
+}
+
+func (s *service) DeleteRoom(playerID, roomID string) error {
+	room, err := s.rooms.Get(roomID)
+	if err != nil {
+		return ErrRoomNotFound
+	}
+	if hostOf(room) != playerID {
+		return ErrNotRoomHost
+	}
+	if room.Status != StatusWaiting {
+		return ErrRoomInProgress
+	}
+
+	if err := s.rooms.Delete(roomID); err != nil {
+		return err
+	}
+	s.roomDeleted(room)
+	return nil
+}
+
+// ホストを返す（ホストの列が無かった頃のルームは作成者）
+func hostOf(room *model.Room) string {
+	if room.Host != "" {
+		return room.Host
+	}
+	return room.Player1
+}
+
+// 同じルームのもう一方のプレイヤーを返す（いなければ空文字）
+func opponentOf(room *model.Room, playerID string) string {
+	switch {
+	case room.Player1 == playerID && room.Player2 != nil:
+		return *room.Player2
+	case room.Player2 != nil && *room.Player2 == playerID:
+		return room.Player1
+	}
+	return ""
+}
+
+// 席を空けて対戦相手待ちに戻す（Player1 が抜けた場合は Player2 を繰り上げ、残った1人をホストにする）
+func vacate(room *model.Room, playerID string) error {
+	if err := checkTransition(room.Status, StatusWaiting); err != nil {
+		return ErrGameStarted
+	}
+	if room.Player1 == playerID && room.Player2 != nil {
+		room.Player1 = *room.Player2
+	}
+	room.Player2 = nil
+	room.IsFull = false
+	room.Host = room.Player1
+	room.Status = StatusWaiting
+	return nil
+}
+
+// 最後の1人が退出する（ルームを閉じる）
+var errLastPlayer = errors.New("last player in room")
+
+func (s *service) KickPlayer(hostID, roomID string) (string, error) {
+	var kicked string
+	room, err := s.rooms.Update(roomID, func(room *model.Room) error {
+		if hostOf(room) != hostID {
+			return ErrNotRoomHost
+		}
+		if !IsPreGame(room.Status) {
+			return ErrGameStarted
+		}
+		if kicked = opponentOf(room, hostID); kicked == "" {
+			return ErrNoOpponent
+		}
+		return vacate(room, kicked)
+	})
+	if err != nil {
+		return "", err
+	}
+	s.roomUpdated(room)
+	return kicked, nil
+}
+
+func (s *service) TransferHost(hostID, roomID string) (*RoomResponse, error) {
+	room, err := s.rooms.Update(roomID, func(room *model.Room) error {
+		if hostOf(room) != hostID {
+			return ErrNotRoomHost
+		}
+		opponent := opponentOf(room, hostID)
+		if opponent == "" {
+			return ErrNoOpponent
+		}
+		room.Host = opponent
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.roomUpdated(room), nil
+}
+
+func (s *service) LeaveRoom(playerID, roomID string) (bool, error) {
+	room, err := s.rooms.Update(roomID, func(room *model.Room) error {
+		if room.Player1 != playerID && (room.Player2 == nil || *room.Player2 != playerID) {
+			return ErrNotInRoom
+		}
+		if !IsPreGame(room.Status) {
+			return ErrGameStarted
+		}
+		if room.Player2 == nil {
+			return errLastPlayer
+		}
+		return vacate(room, playerID)
+	})
+	if errors.Is(err, errLastPlayer) {
+		if err := s.CloseRoom(roomID); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s.roomUpdated(room)
+	return false, nil
+}
+
+// 変更したルームを公開ルームであればロビーに通知する
+// @return *RoomResponse 変更後のルーム
+func (s *service) roomUpdated(room *model.Room) *RoomResponse {
+	resp := s.toResponse(room)
+	if !room.IsPrivate {
+		s.notify.Broadcast(map[string]interface{}{"type": EventRoomUpdated, "room": resp})
+	}
+	return resp
+}
+
+// 削除したルームを後片付けし、公開ルームであればロビーに通知する
+func (s *service) roomDeleted(room *model.Room) {
+	s.scoreMu.Lock()
+	delete(s.scores, room.ID)
+	s.scoreMu.Unlock()
+	if !room.IsPrivate {
+		s.notify.Broadcast(map[string]interface{}{"type": EventRoomDeleted, "roomID": room.ID})
+	}
+}
+
+// 自動マッチングで成立した対局のルームを作成し、ロビーに通知する
+// @param blackID 先手（Player1）
+// @param whiteID 後手（Player2）
+// @param variant 持ち時間のプリセット
+// @return *model.Room 作成したルーム
+func (s *service) CreateMatchRoom(blackID, whiteID, variant string) (*model.Room, error) {
+	control, ok := Variants[variant]
+	if !ok {
+		return nil, ErrUnknownVariant
+	}
+	room := &model.Room{
+		ID:      uuid.New().String(),
+		Player1: blackID,
+		Player2: &whiteID,
+		Host:    blackID,
+		IsFull:  true,
+		Status:  StatusReady,
+		Variant: variant,
+		// 先手は組み合わせ時に決めてあるので、再戦で入れ替えるだけにする
+		ColorMode: ColorModeAlternate,
+		Opening:   OpeningStandard,
+		BoardSize: reversi.DefaultSize,
+	}
+	SetTimeControl(room, control)
+
+	if err := s.rooms.Create(room); err != nil {
+		return nil, err
+	}
+
+	s.notify.Broadcast(map[string]interface{}{"type": EventRoomCreated, "room": s.toResponse(room)})
+	return room, nil
+}
+
+func (s *service) Room(roomID string) (*model.Room, error) {
+	return s.rooms.Get(roomID)
+}
+
+func (s *service) CloseRoom(roomID string) error {
+	room, err := s.rooms.Get(roomID)
+	if err != nil {
+		return err
+	}
+	if err := s.rooms.Delete(roomID); err != nil {
+		return err
+	}
+	s.roomDeleted(room)
+	return nil
+}
+
+func (s *service) ExpireRooms(before time.Time) (int, error) {
+	deleted, err := s.rooms.DeleteCreatedBefore(before)
+	for _, room := range deleted {
+		s.roomDeleted(room)
+	}
+	return len(deleted), err
+}
+
+// 対局の進行状況を記録し、公開ルームであればロビーに room_status を通知する
+// 状態が変わる場合は遷移できるかを確かめてから永続化する（石数はメモリ上のみ）
+// @param roomID 対象のルーム
+// @param status 新しい状態（変わらなければ石数の更新のみ）
+// @param score 現在の石数
+// @return error 遷移できなければ ErrInvalidTransition
+func (s *service) ReportStatus(roomID, status string, score Score) error {
+	room, err := s.rooms.Get(roomID)
+	if err != nil {
+		return err
+	}
+	if room.Status != status {
+		room, err = s.rooms.Update(roomID, func(room *model.Room) error {
+			if room.Status == status {
+				return nil
+			}
+			if err := checkTransition(room.Status, status); err != nil {
+				return err
+			}
+			room.Status = status
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.scoreMu.Lock()
+	s.scores[roomID] = score
+	s.scoreMu.Unlock()
+
+	if !room.IsPrivate {
+		s.notify.Broadcast(map[string]interface{}{
+			"type":   EventRoomStatus,
+			"roomID": roomID,
+			"status": status,
+			"score":  score,
+		})
+	}
+	return nil
+}
+
+// 対局中と記録されているのに対局が残っていないルームを、準備完了の確認からやり直せる状態に戻す
+// 遷移表にない戻し方なので、対局を管理する側が対局を持っていないと確かめてから呼ぶこと
+// @param roomID 対象のルーム
+// @return *model.Room 戻した後のルーム（対局中でなければそのまま）
+// @return error ルームがなければ ErrRoomNotFound
+func (s *service) RecoverRoom(roomID string) (*model.Room, error) {
+	room, err := s.rooms.Get(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.Status != StatusInProgress {
+		return room, nil
+	}
+	room, err = s.rooms.Update(roomID, func(room *model.Room) error {
+		if room.Status != StatusInProgress {
+			return nil
+		}
+		room.Status = StatusWaiting
+		if room.Player2 != nil {
+			room.Status = StatusReady
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.scoreMu.Lock()
+	delete(s.scores, roomID)
+	s.scoreMu.Unlock()
+
+	if !room.IsPrivate {
+		s.notify.Broadcast(map[string]interface{}{
+			"type":   EventRoomStatus,
+			"roomID": roomID,
+			"status": room.Status,
+		})
+	}
+	return room, nil
+}
@@ -0,0 +1,384 @@
+package lobby
+
+import (
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	"be-binareversi/repository"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// プレイヤーを登録したメモリ上のリポジトリで Service を作成する
+func newService(rec *recorder, players ...string) Service {
+	repos := repository.NewMemory()
+	for _, id := range players {
+		repos.Players.Create(&model.Player{ID: id, Name: id})
+	}
+	return New(repos.Players, NewRegistry(repos.Rooms), rec)
+}
+
+// 配信されたイベントの種類を記録する Notifier
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) Broadcast(event map[string]interface{}) {
+	r.events = append(r.events, event["type"].(string))
+}
+
+func Test01_CreateAndJoinPublicRoom(t *testing.T) {
+	rec := &recorder{}
+	svc := newService(rec, "alice", "bob")
+
+	room, err := svc.CreateRoom("alice", RoomOptions{Variant: "blitz"})
+	if err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	if room.Variant != "blitz" || room.InitialSeconds != 180 || room.IncrementSeconds != 2 {
+		t.Errorf("Unexpected time control: %+v", room)
+	}
+
+	joined, err := svc.JoinRoom("bob", room.ID, JoinOptions{})
+	if err != nil {
+		t.Fatalf("JoinRoom failed: %v", err)
+	}
+	if !joined.IsFull || joined.Player2 != "bob" {
+		t.Errorf("Expected full room with bob, got %+v", joined)
+	}
+	if len(rec.events) != 2 || rec.events[0] != EventRoomCreated || rec.events[1] != EventRoomUpdated {
+		t.Errorf("Unexpected broadcasts: %v", rec.events)
+	}
+
+	if _, err := svc.JoinRoom("alice", room.ID, JoinOptions{}); !errors.Is(err, ErrRoomFull) {
+		t.Errorf("Expected ErrRoomFull, got %v", err)
+	}
+}
+
+func Test02_PrivateRoomRequiresInviteCode(t *testing.T) {
+	rec := &recorder{}
+	svc := newService(rec, "alice", "bob")
+
+	room, err := svc.CreateRoom("alice", RoomOptions{Private: true})
+	if err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	if room.InviteCode == "" {
+		t.Fatal("Expected invite code for private room")
+	}
+
+	if _, err := svc.JoinRoom("bob", room.ID, JoinOptions{InviteCode: "WRONG1"}); !errors.Is(err, ErrRoomAccessDenied) {
+		t.Errorf("Expected ErrRoomAccessDenied, got %v", err)
+	}
+	if _, err := svc.JoinRoom("bob", "", JoinOptions{InviteCode: room.InviteCode}); err != nil {
+		t.Errorf("Expected join by invite code, got %v", err)
+	}
+	if len(rec.events) != 0 {
+		t.Errorf("Private room should not be broadcast, got %v", rec.events)
+	}
+}
+
+func Test03_ReservedRoom(t *testing.T) {
+	svc := newService(&recorder{}, "alice", "bob", "carol")
+
+	room, _ := svc.CreateRoom("alice", RoomOptions{ReservedFor: "bob"})
+	if _, err := svc.JoinRoom("carol", room.ID, JoinOptions{}); !errors.Is(err, ErrRoomReserved) {
+		t.Errorf("Expected ErrRoomReserved, got %v", err)
+	}
+	if _, err := svc.JoinRoom("bob", room.ID, JoinOptions{}); err != nil {
+		t.Errorf("Expected reserved player to join, got %v", err)
+	}
+}
+
+func Test04_InvalidOptions(t *testing.T) {
+	svc := newService(&recorder{}, "alice")
+
+	if _, err := svc.CreateRoom("alice", RoomOptions{Variant: "bullet"}); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("Expected ErrUnknownVariant, got %v", err)
+	}
+	if _, err := svc.CreateRoom("alice", RoomOptions{TimeControl: "fischer"}); !errors.Is(err, ErrInvalidControl) {
+		t.Errorf("Expected ErrInvalidControl, got %v", err)
+	}
+	if _, err := svc.CreateRoom("nobody", RoomOptions{}); !errors.Is(err, ErrInvalidPlayer) {
+		t.Errorf("Expected ErrInvalidPlayer, got %v", err)
+	}
+}
+
+func Test05_DeleteRoomHostOnly(t *testing.T) {
+	rec := &recorder{}
+	svc := newService(rec, "alice", "bob")
+
+	room, _ := svc.CreateRoom("alice", RoomOptions{})
+	if err := svc.DeleteRoom("bob", room.ID); !errors.Is(err, ErrNotRoomHost) {
+		t.Errorf("Expected ErrNotRoomHost, got %v", err)
+	}
+	if err := svc.DeleteRoom("alice", room.ID); err != nil {
+		t.Fatalf("DeleteRoom failed: %v", err)
+	}
+	if _, err := svc.GetRoom("alice", room.ID); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("Expected ErrRoomNotFound after delete, got %v", err)
+	}
+	if rec.events[len(rec.events)-1] != EventRoomDeleted {
+		t.Errorf("Expected room_deleted broadcast, got %v", rec.events)
+	}
+}
+
+func Test06_ListRoomsFilter(t *testing.T) {
+	svc := newService(&recorder{}, "alice", "bob", "carol")
+
+	blitz, _ := svc.CreateRoom("alice", RoomOptions{Variant: "blitz"})
+	svc.CreateRoom("carol", RoomOptions{})
+	svc.CreateRoom("carol", RoomOptions{Private: true})
+	svc.JoinRoom("bob", blitz.ID, JoinOptions{})
+
+	all, _ := svc.ListRooms(RoomFilter{})
+	if len(all.Rooms) != 2 {
+		t.Errorf("Expected 2 public rooms, got %d", len(all.Rooms))
+	}
+	open, _ := svc.ListRooms(RoomFilter{Status: "open"})
+	if len(open.Rooms) != 1 || open.Rooms[0].Variant != VariantStandard {
+		t.Errorf("Expected 1 open standard room, got %+v", open.Rooms)
+	}
+	full, _ := svc.ListRooms(RoomFilter{Status: "full", Variant: "blitz"})
+	if len(full.Rooms) != 1 || full.Rooms[0].ID != blitz.ID || full.Rooms[0].Player2 != "bob" {
+		t.Errorf("Expected full blitz room, got %+v", full.Rooms)
+	}
+	limited, _ := svc.ListRooms(RoomFilter{Limit: 1, Offset: 1})
+	if len(limited.Rooms) != 1 {
+		t.Errorf("Expected 1 room with limit/offset, got %d", len(limited.Rooms))
+	}
+}
+
+func Test14_HostControls(t *testing.T) {
+	rec := &recorder{}
+	svc := newService(rec, "alice", "bob", "carol")
+	room, _ := svc.CreateRoom("alice", RoomOptions{})
+	svc.JoinRoom("bob", room.ID, JoinOptions{})
+
+	if _, err := svc.KickPlayer("bob", room.ID); !errors.Is(err, ErrNotRoomHost) {
+		t.Errorf("Expected ErrNotRoomHost, got %v", err)
+	}
+	if kicked, err := svc.KickPlayer("alice", room.ID); err != nil || kicked != "bob" {
+		t.Fatalf("Expected bob to be kicked, got %q, %v", kicked, err)
+	}
+	if _, err := svc.KickPlayer("alice", room.ID); !errors.Is(err, ErrNoOpponent) {
+		t.Errorf("Expected ErrNoOpponent, got %v", err)
+	}
+
+	svc.JoinRoom("carol", room.ID, JoinOptions{})
+	resp, err := svc.TransferHost("alice", room.ID)
+	if err != nil || resp.HostID != "carol" {
+		t.Fatalf("Expected carol to become host, got %+v, %v", resp, err)
+	}
+	if err := svc.DeleteRoom("alice", room.ID); !errors.Is(err, ErrNotRoomHost) {
+		t.Errorf("Expected former host to lose controls, got %v", err)
+	}
+
+	// 先手の席が空いたら残ったプレイヤーを繰り上げ、ホストにする
+	if kicked, err := svc.KickPlayer("carol", room.ID); err != nil || kicked != "alice" {
+		t.Fatalf("Expected alice to be kicked, got %q, %v", kicked, err)
+	}
+	got, _ := svc.GetRoom("carol", room.ID)
+	if got.Player1 != "carol" || got.Player2 != "" || got.HostID != "carol" || got.IsFull {
+		t.Errorf("Expected carol alone as host, got %+v", got)
+	}
+
+	rec.events = nil
+	if closed, err := svc.LeaveRoom("carol", room.ID); err != nil || !closed {
+		t.Errorf("Expected last player to close the room, got %v, %v", closed, err)
+	}
+	if len(rec.events) != 1 || rec.events[0] != EventRoomDeleted {
+		t.Errorf("Expected room_deleted broadcast, got %v", rec.events)
+	}
+}
+
+func Test15_LeaveRoom(t *testing.T) {
+	svc := newService(&recorder{}, "alice", "bob")
+	room, _ := svc.CreateRoom("alice", RoomOptions{})
+	svc.JoinRoom("bob", room.ID, JoinOptions{})
+
+	if closed, err := svc.LeaveRoom("alice", room.ID); err != nil || closed {
+		t.Fatalf("Expected host to leave an occupied room, got %v, %v", closed, err)
+	}
+	got, _ := svc.GetRoom("bob", room.ID)
+	if got.Player1 != "bob" || got.HostID != "bob" {
+		t.Errorf("Expected bob to take over the room, got %+v", got)
+	}
+	if _, err := svc.LeaveRoom("alice", room.ID); !errors.Is(err, ErrNotInRoom) {
+		t.Errorf("Expected ErrNotInRoom, got %v", err)
+	}
+
+	svc.JoinRoom("alice", room.ID, JoinOptions{})
+	svc.ReportStatus(room.ID, StatusInProgress, Score{Black: 2, White: 2})
+	if _, err := svc.LeaveRoom("alice", room.ID); !errors.Is(err, ErrGameStarted) {
+		t.Errorf("Expected ErrGameStarted, got %v", err)
+	}
+	if _, err := svc.KickPlayer("bob", room.ID); !errors.Is(err, ErrGameStarted) {
+		t.Errorf("Expected ErrGameStarted, got %v", err)
+	}
+}
+
+func Test16_RoomStateMachine(t *testing.T) {
+	for _, c := range []struct {
+		from, to string
+		ok       bool
+	}{
+		{StatusWaiting, StatusReady, true},
+		{StatusWaiting, StatusInProgress, false},
+		{StatusReady, StatusInProgress, true},
+		{StatusReady, StatusWaiting, true},
+		{StatusInProgress, StatusWaiting, false},
+		{StatusInProgress, StatusAbandoned, true},
+		{StatusFinished, StatusInProgress, true},
+		{StatusAbandoned, StatusInProgress, false},
+	} {
+		if got := CanTransition(c.from, c.to); got != c.ok {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", c.from, c.to, got, c.ok)
+		}
+	}
+
+	svc := newService(&recorder{}, "alice", "bob")
+	room, _ := svc.CreateRoom("alice", RoomOptions{})
+	if err := svc.ReportStatus(room.ID, StatusInProgress, Score{}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected a game without an opponent to be rejected, got %v", err)
+	}
+	joined, _ := svc.JoinRoom("bob", room.ID, JoinOptions{})
+	if joined.Status != StatusReady {
+		t.Errorf("Expected ready after join, got %q", joined.Status)
+	}
+	svc.KickPlayer("alice", room.ID)
+	if got, _ := svc.GetRoom("alice", room.ID); got.Status != StatusWaiting {
+		t.Errorf("Expected waiting after kick, got %q", got.Status)
+	}
+}
+
+func Test17_ColorMode(t *testing.T) {
+	svc := newService(&recorder{}, "alice", "bob")
+
+	// 指定がなければ抽選にする
+	room, err := svc.CreateRoom("alice", RoomOptions{})
+	if err != nil || room.ColorMode != ColorModeRandom {
+		t.Errorf("Expected random by default, got %+v, %v", room, err)
+	}
+	room, _ = svc.CreateRoom("alice", RoomOptions{ColorMode: ColorModeWhite})
+	if got, _ := svc.GetRoom("bob", room.ID); got.ColorMode != ColorModeWhite {
+		t.Errorf("Expected white to be stored, got %q", got.ColorMode)
+	}
+	if _, err := svc.CreateRoom("alice", RoomOptions{ColorMode: "purple"}); !errors.Is(err, ErrUnknownColorMode) {
+		t.Errorf("Expected ErrUnknownColorMode, got %v", err)
+	}
+
+	// 組み合わせで作るルームは決めた先手のまま、再戦で入れ替える
+	match, _ := svc.CreateMatchRoom("alice", "bob", VariantStandard)
+	if match.ColorMode != ColorModeAlternate {
+		t.Errorf("Expected alternate for match rooms, got %q", match.ColorMode)
+	}
+}
+
+func Test18_Opening(t *testing.T) {
+	svc := newService(&recorder{}, "alice")
+
+	room, err := svc.CreateRoom("alice", RoomOptions{Opening: OpeningHandicap, Handicap: 2})
+	if err != nil || room.Opening != OpeningHandicap || room.Handicap != 2 {
+		t.Fatalf("Expected handicap room, got %+v, %v", room, err)
+	}
+	stored, _ := svc.Room(room.ID)
+	setup, err := SetupOf(stored, reversi.White, rand.Intn)
+	if err != nil || setup.Board[0][0] != reversi.White || setup.Board[7][7] != reversi.White {
+		t.Errorf("Expected white handicap corners, got %v, %v", setup.Board, err)
+	}
+
+	// 局面文字列は正規化して保存する
+	position := strings.ToLower(reversi.StandardSetup(reversi.DefaultSize).String())
+	room, err = svc.CreateRoom("alice", RoomOptions{Opening: OpeningCustom, Position: position})
+	if err != nil || room.Position != reversi.StandardSetup(reversi.DefaultSize).String() {
+		t.Errorf("Expected normalized custom position, got %+v, %v", room, err)
+	}
+
+	for _, opts := range []RoomOptions{
+		{Opening: OpeningHandicap, Handicap: 5},
+		{Opening: OpeningCustom, Position: strings.Repeat("-", 64)},
+		{Opening: "mirror"},
+	} {
+		if _, err := svc.CreateRoom("alice", opts); !errors.Is(err, ErrInvalidOpening) {
+			t.Errorf("Expected ErrInvalidOpening for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func Test19_BoardSize(t *testing.T) {
+	svc := newService(&recorder{}, "alice")
+
+	room, err := svc.CreateRoom("alice", RoomOptions{})
+	if err != nil || room.BoardSize != reversi.DefaultSize {
+		t.Errorf("Expected 8x8 by default, got %+v, %v", room, err)
+	}
+	room, err = svc.CreateRoom("alice", RoomOptions{BoardSize: 6, Opening: OpeningHandicap, Handicap: 1})
+	if err != nil || room.BoardSize != 6 {
+		t.Fatalf("Expected 6x6 handicap room, got %+v, %v", room, err)
+	}
+	stored, _ := svc.Room(room.ID)
+	if setup, err := SetupOf(stored, reversi.Black, rand.Intn); err != nil || len(setup.Board) != 6 || setup.Board[0][0] != reversi.Black {
+		t.Errorf("Expected 6x6 handicap setup, got %v, %v", setup.Board, err)
+	}
+
+	if _, err := svc.CreateRoom("alice", RoomOptions{BoardSize: 7}); !errors.Is(err, ErrInvalidBoardSize) {
+		t.Errorf("Expected ErrInvalidBoardSize, got %v", err)
+	}
+	// 局面文字列は盤面の大きさと合っていなければならない
+	position := reversi.StandardSetup(reversi.DefaultSize).String()
+	if _, err := svc.CreateRoom("alice", RoomOptions{BoardSize: 10, Opening: OpeningCustom, Position: position}); !errors.Is(err, ErrInvalidOpening) {
+		t.Errorf("Expected ErrInvalidOpening for a mismatched position, got %v", err)
+	}
+}
+
+func Test20_SpectatorAccess(t *testing.T) {
+	svc := newService(&recorder{}, "alice")
+
+	// 観戦は作成者が許可した公開ルームに限る
+	for _, c := range []struct {
+		opts RoomOptions
+		want bool
+	}{
+		{RoomOptions{}, false},
+		{RoomOptions{AllowSpectators: true}, true},
+		{RoomOptions{AllowSpectators: true, Private: true}, false},
+	} {
+		resp, err := svc.CreateRoom("alice", c.opts)
+		if err != nil {
+			t.Fatalf("CreateRoom failed: %v", err)
+		}
+		room, _ := svc.Room(resp.ID)
+		if got := CanSpectate(room); got != c.want || resp.AllowSpectators != c.opts.AllowSpectators {
+			t.Errorf("CanSpectate(%+v) = %v, want %v", c.opts, got, c.want)
+		}
+	}
+}
+
+func Test21_RecoverRoom(t *testing.T) {
+	notify := &recorder{}
+	svc := newService(notify, "alice", "bob")
+	room, _ := svc.CreateRoom("alice", RoomOptions{})
+	svc.JoinRoom("bob", room.ID, JoinOptions{})
+	svc.ReportStatus(room.ID, StatusInProgress, Score{Black: 2, White: 2})
+
+	// 失われた対局は準備完了の確認からやり直す
+	recovered, err := svc.RecoverRoom(room.ID)
+	if err != nil || recovered.Status != StatusReady {
+		t.Fatalf("Expected recovered room to be ready, got %+v, %v", recovered, err)
+	}
+	if got, _ := svc.GetRoom("alice", room.ID); got.Status != StatusReady || got.Score != nil {
+		t.Errorf("Expected ready room without a score, got %+v", got)
+	}
+	if last := notify.events[len(notify.events)-1]; last != EventRoomStatus {
+		t.Errorf("Expected room_status to be broadcast, got %s", last)
+	}
+
+	// 対局中でなければ何もしない
+	svc.ReportStatus(room.ID, StatusInProgress, Score{})
+	svc.ReportStatus(room.ID, StatusFinished, Score{})
+	if recovered, _ := svc.RecoverRoom(room.ID); recovered.Status != StatusFinished {
+		t.Errorf("Expected finished room to stay finished, got %q", recovered.Status)
+	}
+}
@@ -0,0 +1,6 @@
+package lobby
+
+// Notifier はロビーの購読者へイベントを配信する
+type Notifier interface {
+	Broadcast(event map[string]interface{})
+}
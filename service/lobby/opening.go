@@ -0,0 +1,69 @@
+package lobby
+
+import (
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	"errors"
+	"fmt"
+)
+
+// 開始局面の種類
+const (
+	OpeningStandard = "standard" // 中央に4石
+	OpeningHandicap = "handicap" // レーティングの低い側に角の石を置く（Handicap で角の数を指定）
+	OpeningRandom   = "random"   // 互角の局面を対局ごとに抽選する
+	OpeningCustom   = "custom"   // 局面文字列（Position）で指定する
+)
+
+var ErrInvalidOpening = errors.New("invalid opening")
+
+// ルームの開始局面を設定する（盤面の大きさを決めた後に呼ぶ）
+// @param room 設定先のルーム
+// @param opts 作成時の指定（Opening が空なら standard）
+// @return error 不正な指定、または対局を始められない局面であればエラー
+func applyOpening(room *model.Room, opts RoomOptions) error {
+	room.Handicap, room.Position = 0, ""
+	switch opts.Opening {
+	case "", OpeningStandard:
+		room.Opening = OpeningStandard
+	case OpeningRandom:
+		room.Opening = OpeningRandom
+	case OpeningHandicap:
+		// どちらの色が受けても局面の正しさは変わらないので黒で確かめる
+		if _, err := reversi.HandicapSetup(BoardSizeOf(room), reversi.Black, opts.Handicap); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidOpening, err)
+		}
+		room.Opening, room.Handicap = OpeningHandicap, opts.Handicap
+	case OpeningCustom:
+		setup, err := reversi.ParseSetup(opts.Position)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidOpening, err)
+		}
+		if size := len(setup.Board); size != BoardSizeOf(room) {
+			return fmt.Errorf("%w: position is %dx%d but the board is %dx%d", ErrInvalidOpening, size, size, BoardSizeOf(room), BoardSizeOf(room))
+		}
+		room.Opening, room.Position = OpeningCustom, setup.String()
+	default:
+		return fmt.Errorf("%w: unknown opening %q", ErrInvalidOpening, opts.Opening)
+	}
+	return nil
+}
+
+// ルームの設定から開始局面を作る
+// @param room ルーム
+// @param weaker handicap でハンデを受ける色
+// @param intn random で使う乱数（rand.Intn など）
+// @return reversi.Setup 開始局面
+// @return error 保存された設定が不正であればエラー
+func SetupOf(room *model.Room, weaker int, intn func(n int) int) (reversi.Setup, error) {
+	size := BoardSizeOf(room)
+	switch room.Opening {
+	case OpeningHandicap:
+		return reversi.HandicapSetup(size, weaker, room.Handicap)
+	case OpeningRandom:
+		return reversi.RandomSetup(size, intn), nil
+	case OpeningCustom:
+		return reversi.ParseSetup(room.Position)
+	}
+	return reversi.StandardSetup(size), nil
+}
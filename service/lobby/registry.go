@@ -0,0 +1,160 @@
+package lobby
+
+import (
+	"be-binareversi/model"
+	"be-binareversi/repository"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Registry はルームのキャッシュと永続化先を一致させたまま読み書きする
+// ルームの変更は必ず Registry を通し、永続化に成功した内容だけをキャッシュに反映する
+type Registry struct {
+	mu    sync.RWMutex
+	repo  repository.RoomRepository
+	rooms map[string]*model.Room
+}
+
+// Registry を作成する（起動時は Warm でキャッシュを読み込む）
+func NewRegistry(repo repository.RoomRepository) *Registry {
+	return &Registry{repo: repo, rooms: make(map[string]*model.Room)}
+}
+
+// 永続化先の全ルームをキャッシュに読み込む
+// @return int 読み込んだ件数
+func (r *Registry) Warm() (int, error) {
+	rooms, err := r.repo.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rooms = make(map[string]*model.Room, len(rooms))
+	for _, room := range rooms {
+		r.rooms[room.ID] = room
+	}
+	return len(rooms), nil
+}
+
+// ルームを取得する（キャッシュに無ければ永続化先から読み込む）
+// @return *model.Room ルームのコピー
+func (r *Registry) Get(id string) (*model.Room, error) {
+	r.mu.RLock()
+	room, ok := r.rooms[id]
+	r.mu.RUnlock()
+	if ok {
+		copied := *room
+		return &copied, nil
+	}
+
+	room, err := r.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+	r.mu.Lock()
+	if _, ok := r.rooms[id]; !ok {
+		r.rooms[id] = room
+	}
+	r.mu.Unlock()
+	copied := *room
+	return &copied, nil
+}
+
+// 招待コードのハッシュからルームを取得する
+func (r *Registry) GetByInviteCodeHash(hash string) (*model.Room, error) {
+	r.mu.RLock()
+	for _, room := range r.rooms {
+		if room.InviteCodeHash != "" && room.InviteCodeHash == hash {
+			copied := *room
+			r.mu.RUnlock()
+			return &copied, nil
+		}
+	}
+	r.mu.RUnlock()
+
+	room, err := r.repo.GetByInviteCodeHash(hash)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	return r.Get(room.ID)
+}
+
+// 公開ルームの一覧を永続化先から取得する（キャッシュのロックは取らない）
+// 書き込みは永続化に成功してからキャッシュへ反映するため、永続化先は常にキャッシュ以上に新しい
+func (r *Registry) ListPublic(query repository.RoomQuery) ([]*repository.RoomListing, error) {
+	return r.repo.ListPublic(query)
+}
+
+// ルームを作成する
+func (r *Registry) Create(room *model.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.repo.Create(room); err != nil {
+		return err
+	}
+	copied := *room
+	r.rooms[room.ID] = &copied
+	return nil
+}
+
+// ルームを排他的に変更する
+// fn がエラーを返した場合や永続化に失敗した場合はキャッシュを変更しない
+// @param id 対象のルーム
+// @param fn ルームのコピーを受け取り変更する
+// @return *model.Room 変更後のルームのコピー
+func (r *Registry) Update(id string, fn func(room *model.Room) error) (*model.Room, error) {
+	if _, err := r.Get(id); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cached, ok := r.rooms[id]
+	if !ok {
+		// Get の後に削除された
+		return nil, ErrRoomNotFound
+	}
+	room := *cached
+	if err := fn(&room); err != nil {
+		return nil, err
+	}
+	if err := r.repo.Update(&room); err != nil {
+		return nil, err
+	}
+	r.rooms[id] = &room
+	copied := room
+	return &copied, nil
+}
+
+// ルームを削除する
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.repo.Delete(id); err != nil {
+		return err
+	}
+	delete(r.rooms, id)
+	return nil
+}
+
+// 指定時刻より前に作成されたルームを削除する
+// @return []*model.Room 削除したルーム
+func (r *Registry) DeleteCreatedBefore(before time.Time) ([]*model.Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.repo.DeleteCreatedBefore(before); err != nil {
+		return nil, err
+	}
+	deleted := []*model.Room{}
+	for id, room := range r.rooms {
+		if room.CreatedAt.Before(before) {
+			deleted = append(deleted, room)
+			delete(r.rooms, id)
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,169 @@
+package lobby
+
+import (
+	"be-binareversi/model"
+	"be-binareversi/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test07_RoomsSurviveRestart(t *testing.T) {
+	repos := repository.NewMemory()
+	repos.Players.Create(&model.Player{ID: "alice", Name: "alice"})
+	repos.Players.Create(&model.Player{ID: "bob", Name: "bob"})
+
+	before := New(repos.Players, NewRegistry(repos.Rooms), &recorder{})
+	room, _ := before.CreateRoom("alice", RoomOptions{})
+
+	// 再起動後は永続化先からキャッシュを読み込む
+	registry := NewRegistry(repos.Rooms)
+	if n, err := registry.Warm(); err != nil || n != 1 {
+		t.Fatalf("Expected 1 room after warm-up, got %d, %v", n, err)
+	}
+	after := New(repos.Players, registry, &recorder{})
+	if page, _ := after.ListRooms(RoomFilter{}); len(page.Rooms) != 1 {
+		t.Errorf("Expected room in list after restart, got %d", len(page.Rooms))
+	}
+	if _, err := after.JoinRoom("bob", room.ID, JoinOptions{}); err != nil {
+		t.Errorf("Expected join after restart, got %v", err)
+	}
+}
+
+func Test08_ClosedRoomCannotBeJoined(t *testing.T) {
+	svc := newService(&recorder{}, "alice", "bob")
+	room, _ := svc.CreateRoom("alice", RoomOptions{})
+
+	if err := svc.CloseRoom(room.ID); err != nil {
+		t.Fatalf("CloseRoom failed: %v", err)
+	}
+	if _, err := svc.JoinRoom("bob", room.ID, JoinOptions{}); !errors.Is(err, ErrRoomFull) {
+		t.Errorf("Expected closed room to be unjoinable, got %v", err)
+	}
+	if page, _ := svc.ListRooms(RoomFilter{}); len(page.Rooms) != 0 {
+		t.Errorf("Expected closed room to disappear from list, got %d", len(page.Rooms))
+	}
+}
+
+func Test09_ExpireRooms(t *testing.T) {
+	repos := repository.NewMemory()
+	registry := NewRegistry(repos.Rooms)
+	registry.Create(&model.Room{ID: "old", Player1: "a", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	registry.Create(&model.Room{ID: "new", Player1: "a"})
+	svc := New(repos.Players, registry, &recorder{})
+
+	if n, err := svc.ExpireRooms(time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Fatalf("Expected 1 expired room, got %d, %v", n, err)
+	}
+	if _, err := registry.Get("old"); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("Expected expired room to be gone, got %v", err)
+	}
+	if _, err := repos.Rooms.GetByID("old"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected expired room to be deleted from storage, got %v", err)
+	}
+}
+
+func Test10_RegistryCacheConsistency(t *testing.T) {
+	repos := repository.NewMemory()
+	registry := NewRegistry(repos.Rooms)
+	registry.Create(&model.Room{ID: "r", Player1: "a"})
+
+	// 失敗した変更はキャッシュにも永続化先にも残らない
+	_, err := registry.Update("r", func(room *model.Room) error {
+		room.IsFull = true
+		return ErrRoomFull
+	})
+	if !errors.Is(err, ErrRoomFull) {
+		t.Fatalf("Expected update error, got %v", err)
+	}
+	if room, _ := registry.Get("r"); room.IsFull {
+		t.Error("Expected failed update not to be cached")
+	}
+
+	// 取得したルームを書き換えてもキャッシュは変わらない
+	room, _ := registry.Get("r")
+	room.Player1 = "changed"
+	if cached, _ := registry.Get("r"); cached.Player1 != "a" {
+		t.Error("Expected Get to return a copy")
+	}
+
+	// 変更はキャッシュと永続化先の両方に反映される
+	registry.Update("r", func(room *model.Room) error {
+		room.Variant = "blitz"
+		return nil
+	})
+	if stored, _ := repos.Rooms.GetByID("r"); stored.Variant != "blitz" {
+		t.Errorf("Expected update to be persisted, got %q", stored.Variant)
+	}
+}
+
+func Test11_ReportStatus(t *testing.T) {
+	rec := &recorder{}
+	svc := newService(rec, "alice", "bob")
+	room, _ := svc.CreateRoom("alice", RoomOptions{})
+	svc.JoinRoom("bob", room.ID, JoinOptions{})
+
+	if err := svc.ReportStatus(room.ID, StatusInProgress, Score{Black: 4, White: 1}); err != nil {
+		t.Fatalf("ReportStatus failed: %v", err)
+	}
+	got, _ := svc.GetRoom("alice", room.ID)
+	if got.Status != StatusInProgress || got.Score == nil || got.Score.Black != 4 {
+		t.Errorf("Expected in-progress room with score, got %+v", got)
+	}
+	if rec.events[len(rec.events)-1] != EventRoomStatus {
+		t.Errorf("Expected room_status broadcast, got %v", rec.events)
+	}
+	if err := svc.ReportStatus("missing", StatusFinished, Score{}); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("Expected ErrRoomNotFound, got %v", err)
+	}
+}
+
+func Test12_DeletionBroadcasts(t *testing.T) {
+	rec := &recorder{}
+	repos := repository.NewMemory()
+	repos.Players.Create(&model.Player{ID: "alice", Name: "alice"})
+	registry := NewRegistry(repos.Rooms)
+	svc := New(repos.Players, registry, rec)
+
+	closed, _ := svc.CreateRoom("alice", RoomOptions{})
+	private, _ := svc.CreateRoom("alice", RoomOptions{Private: true})
+	registry.Create(&model.Room{ID: "old", Player1: "alice", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	rec.events = nil
+
+	svc.CloseRoom(closed.ID)
+	svc.CloseRoom(private.ID)
+	svc.ExpireRooms(time.Now().Add(-time.Hour))
+
+	// 非公開ルームの削除は通知しない
+	if len(rec.events) != 2 || rec.events[0] != EventRoomDeleted || rec.events[1] != EventRoomDeleted {
+		t.Errorf("Expected 2 room_deleted broadcasts, got %v", rec.events)
+	}
+}
+
+func Test13_ListRoomsCursor(t *testing.T) {
+	svc := newService(&recorder{}, "alice")
+	for i := 0; i < 3; i++ {
+		svc.CreateRoom("alice", RoomOptions{})
+	}
+
+	first, err := svc.ListRooms(RoomFilter{Sort: "newest", Limit: 2})
+	if err != nil || len(first.Rooms) != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected first page with cursor, got %+v, %v", first, err)
+	}
+	second, _ := svc.ListRooms(RoomFilter{Sort: "newest", Limit: 2, Cursor: first.NextCursor})
+	if len(second.Rooms) != 1 || second.NextCursor != "" {
+		t.Errorf("Expected last page without cursor, got %+v", second)
+	}
+	for _, r := range first.Rooms {
+		if r.ID == second.Rooms[0].ID {
+			t.Errorf("Room %s appeared on both pages", r.ID)
+		}
+	}
+
+	if _, err := svc.ListRooms(RoomFilter{Cursor: "not-a-cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := svc.ListRooms(RoomFilter{Sort: "name"}); !errors.Is(err, ErrInvalidSort) {
+		t.Errorf("Expected ErrInvalidSort, got %v", err)
+	}
+}
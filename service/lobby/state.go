@@ -0,0 +1,57 @@
+package lobby
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ルームの進行状況
+const (
+	StatusWaiting    = "waiting"     // 対戦相手を待っている
+	StatusReady      = "ready"       // 2人揃い、両者の準備完了を待っている
+	StatusInProgress = "in_progress" // 対局中
+	StatusFinished   = "finished"    // 決着した
+	StatusAbandoned  = "abandoned"   // 決着せずに放棄された
+)
+
+var ErrInvalidTransition = errors.New("invalid room state transition")
+
+// 状態ごとに移れる先
+var transitions = map[string][]string{
+	StatusWaiting:    {StatusReady},
+	StatusReady:      {StatusWaiting, StatusInProgress},
+	StatusInProgress: {StatusFinished, StatusAbandoned},
+	StatusFinished:   {StatusInProgress}, // 再戦
+	StatusAbandoned:  {},
+}
+
+// from から to へ移れるかどうか
+// @param from 現在の状態
+// @param to 移りたい状態
+// @return bool 移れるなら true
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// from から to へ移れなければエラーを返す
+func checkTransition(from, to string) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// 対局が始まる前の状態かどうか（席の出入りができる）
+func IsPreGame(status string) bool {
+	return status == StatusWaiting || status == StatusReady
+}
+
+// 対局が終わった状態かどうか
+func IsOver(status string) bool {
+	return status == StatusFinished || status == StatusAbandoned
+}
@@ -0,0 +1,68 @@
+package lobby
+
+import (
+	"be-binareversi/libs/clock"
+	"be-binareversi/model"
+	"fmt"
+	"time"
+)
+
+// ルームで選べるルール（持ち時間のプリセット）
+const (
+	VariantStandard = "standard"
+	VariantCustom   = "custom" // 持ち時間を個別に指定した場合
+)
+
+var Variants = map[string]clock.Control{
+	VariantStandard: {},
+	"blitz":         {Mode: clock.ModeFischer, Initial: 3 * time.Minute, Increment: 2 * time.Second},
+	"rapid":         {Mode: clock.ModeFischer, Initial: 10 * time.Minute, Increment: 5 * time.Second},
+}
+
+// ルームの持ち時間を設定する
+// @param room 設定先のルーム
+// @param opts 作成時の指定（variant か個別の持ち時間）
+// @return error 不正な指定であればエラー
+func applyTimeControl(room *model.Room, opts RoomOptions) error {
+	if opts.TimeControl == "" {
+		variant := opts.Variant
+		if variant == "" {
+			variant = VariantStandard
+		}
+		control, ok := Variants[variant]
+		if !ok {
+			return ErrUnknownVariant
+		}
+		room.Variant = variant
+		SetTimeControl(room, control)
+		return nil
+	}
+
+	room.Variant = VariantCustom
+	room.TimeControl = opts.TimeControl
+	room.InitialSeconds = opts.InitialSeconds
+	room.IncrementSeconds = opts.IncrementSeconds
+	room.MoveSeconds = opts.MoveSeconds
+	if err := TimeControlOf(room).Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidControl, err)
+	}
+	return nil
+}
+
+// clock.Control をルームの持ち時間に書き込む
+func SetTimeControl(room *model.Room, control clock.Control) {
+	room.TimeControl = control.Mode
+	room.InitialSeconds = int(control.Initial / time.Second)
+	room.IncrementSeconds = int(control.Increment / time.Second)
+	room.MoveSeconds = int(control.PerMove / time.Second)
+}
+
+// ルームの持ち時間設定を clock.Control に変換する
+func TimeControlOf(room *model.Room) clock.Control {
+	return clock.Control{
+		Mode:      room.TimeControl,
+		Initial:   time.Duration(room.InitialSeconds) * time.Second,
+		Increment: time.Duration(room.IncrementSeconds) * time.Second,
+		PerMove:   time.Duration(room.MoveSeconds) * time.Second,
+	}
+}
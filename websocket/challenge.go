@@ -0,0 +1,243 @@
+package websocket
+
+import (
+	"be-binareversi/service/lobby"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 挑戦が失効するまでの時間
+var ChallengeTimeout = 30 * time.Second
+
+// 挑戦者が希望する色
+const (
+	challengeColorRandom = "random"
+	challengeColorBlack  = "black"
+	challengeColorWhite  = "white"
+)
+
+var (
+	errChallengeSelf      = errors.New("cannot challenge yourself")
+	errPlayerUnavailable  = errors.New("player is not available")
+	errAlreadyChallenging = errors.New("another challenge is pending")
+	errNoSuchChallenge    = errors.New("no such challenge")
+	errUnknownColor       = errors.New("unknown color")
+)
+
+// 相手の応答待ちになっている挑戦
+type challenge struct {
+	id      string
+	from    string
+	to      string
+	variant string
+	color   string       // 挑戦者の色の希望
+	client  *lobbyClient // 挑戦者のロビー接続
+	timer   *time.Timer
+}
+
+// クライアントへ送る挑戦の内容
+func (c *challenge) payload(kind string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":             kind,
+		"challengeID":      c.id,
+		"from":             c.from,
+		"fromName":         c.client.name,
+		"to":               c.to,
+		"variant":          c.variant,
+		"color":            c.color,
+		"expiresInSeconds": int(ChallengeTimeout / time.Second),
+	}
+}
+
+// challengeBook は応答待ちの挑戦を管理する
+type challengeBook struct {
+	mu    sync.Mutex
+	byID  map[string]*challenge
+	hub   *lobbyHub                                     // 在席状況の確認と通知の送り先
+	queue *matchmaker                                   // 承諾時に外すマッチング待ちの列
+	start func(a, b *matchTicket, variant string) error // 承諾された対局を開始する
+}
+
+var challenges = newChallengeBook(lobbyClients, matchQueue, startMatch)
+
+// 新しい挑戦の一覧を作成する
+// @param hub ロビーのハブ
+// @param queue マッチング待ちの列
+// @param start 先手・後手を決めた2人の対局を開始する関数
+// @return *challengeBook 挑戦の一覧
+func newChallengeBook(hub *lobbyHub, queue *matchmaker, start func(a, b *matchTicket, variant string) error) *challengeBook {
+	return &challengeBook{byID: make(map[string]*challenge), hub: hub, queue: queue, start: start}
+}
+
+// 挑戦を送受できる状態か（対局・観戦中でなく、オンラインであること）
+// マッチング待ちの間は挑戦を送れ、承諾されたときに待ちを外す
+func challengeable(status string) bool {
+	return status == presenceIdle || status == presenceInQueue
+}
+
+// 挑戦を受け付けて相手に通知する
+// @param from 挑戦者のロビー接続
+// @param to 挑戦相手
+// @param variant 持ち時間のプリセット（空なら standard）
+// @param color 挑戦者の色の希望（空なら random）
+// @return *challenge 受け付けた挑戦
+// @return error 受け付けられなければエラー
+func (b *challengeBook) open(from *lobbyClient, to, variant, color string) (*challenge, error) {
+	if to == from.playerID {
+		return nil, errChallengeSelf
+	}
+	if variant == "" {
+		variant = lobby.VariantStandard
+	}
+	if _, ok := matchVariants[variant]; !ok {
+		return nil, lobby.ErrUnknownVariant
+	}
+	switch color {
+	case "":
+		color = challengeColorRandom
+	case challengeColorRandom, challengeColorBlack, challengeColorWhite:
+	default:
+		return nil, errUnknownColor
+	}
+	if !challengeable(b.hub.statusOf(from.playerID)) || b.hub.statusOf(to) != presenceIdle {
+		return nil, errPlayerUnavailable
+	}
+
+	b.mu.Lock()
+	for _, c := range b.byID {
+		if c.from == from.playerID {
+			b.mu.Unlock()
+			return nil, errAlreadyChallenging
+		}
+	}
+	c := &challenge{
+		id:      uuid.New().String(),
+		from:    from.playerID,
+		to:      to,
+		variant: variant,
+		color:   color,
+		client:  from,
+	}
+	c.timer = time.AfterFunc(ChallengeTimeout, func() {
+		if expired := b.take(c.id, nil); expired != nil {
+			b.finish(expired, "expired")
+		}
+	})
+	b.byID[c.id] = c
+	b.mu.Unlock()
+
+	b.hub.sendTo(to, c.payload("challenge_received"))
+	return c, nil
+}
+
+// 挑戦に応答する。承諾されればルームを作成して両者に match_found を送る
+// @param client 応答した挑戦相手のロビー接続
+// @param id 挑戦ID
+// @param accept 承諾なら true
+// @return error 応答できなければエラー
+func (b *challengeBook) answer(client *lobbyClient, id string, accept bool) error {
+	c := b.take(id, func(c *challenge) bool { return c.to == client.playerID })
+	if c == nil {
+		return errNoSuchChallenge
+	}
+	if !accept {
+		b.finish(c, "declined")
+		return nil
+	}
+	// 通知してから応答までの間に、どちらかが対局・観戦を始めていないか確かめ直す
+	if !challengeable(b.hub.statusOf(c.from)) || !challengeable(b.hub.statusOf(c.to)) ||
+		!b.claim(c.from) || !b.claim(c.to) {
+		b.finish(c, "unavailable")
+		return errPlayerUnavailable
+	}
+	b.finish(c, "accepted")
+
+	challenger := &matchTicket{playerID: c.from, name: c.client.name, client: c.client}
+	opponent := &matchTicket{playerID: c.to, name: client.name, client: client}
+	black, white := challenger, opponent
+	if c.color == challengeColorWhite || (c.color == challengeColorRandom && rand.Intn(2) == 0) {
+		black, white = opponent, challenger
+	}
+	return b.start(black, white, c.variant)
+}
+
+// 承諾された挑戦の対局者を確保する。マッチング待ちであれば列から外す
+// @param playerID 対局者
+// @return bool 対局を始められなければ false（対局・観戦中、切断済み、自動マッチングで相手が決まった直後）
+func (b *challengeBook) claim(playerID string) bool {
+	switch b.hub.statusOf(playerID) {
+	case presenceIdle:
+		return true
+	case presenceInQueue:
+		if !b.queue.cancel(playerID) {
+			return false
+		}
+		b.hub.setQueued(playerID, false)
+		return true
+	}
+	return false
+}
+
+// 自分の挑戦を取り下げる
+// @param playerID 挑戦者
+// @param id 挑戦ID
+// @return error 取り下げられなければエラー
+func (b *challengeBook) cancel(playerID, id string) error {
+	c := b.take(id, func(c *challenge) bool { return c.from == playerID })
+	if c == nil {
+		return errNoSuchChallenge
+	}
+	b.finish(c, "cancelled")
+	return nil
+}
+
+// 切断したロビー接続からの挑戦をすべて取り下げる
+func (b *challengeBook) removeClient(client *lobbyClient) {
+	b.mu.Lock()
+	var removed []*challenge
+	for id, c := range b.byID {
+		if c.client == client {
+			c.timer.Stop()
+			delete(b.byID, id)
+			removed = append(removed, c)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, c := range removed {
+		b.finish(c, "cancelled")
+	}
+}
+
+// 条件に合う挑戦を一覧から外して返す
+// @param id 挑戦ID
+// @param ok 外してよいかの判定（nil なら常に外す）
+// @return *challenge 外した挑戦（なければ nil）
+func (b *challengeBook) take(id string, ok func(c *challenge) bool) *challenge {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, found := b.byID[id]
+	if !found || (ok != nil && !ok(c)) {
+		return nil
+	}
+	c.timer.Stop()
+	delete(b.byID, id)
+	return c
+}
+
+// 挑戦の結果を両者に通知する
+func (b *challengeBook) finish(c *challenge, result string) {
+	msg := map[string]interface{}{
+		"type":        "challenge_resolved",
+		"challengeID": c.id,
+		"from":        c.from,
+		"to":          c.to,
+		"result":      result,
+	}
+	b.hub.sendTo(c.from, msg)
+	b.hub.sendTo(c.to, msg)
+}
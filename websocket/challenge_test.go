@@ -0,0 +1,197 @@
+package websocket
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// 開始された対局の記録
+type startedMatch struct {
+	black, white string
+	variant      string
+}
+
+// テスト用のハブと挑戦の一覧を作成する
+func newChallengeFixture() (*lobbyHub, *matchmaker, *challengeBook, chan startedMatch) {
+	hub := newLobbyHub()
+	go hub.run()
+	queue := &matchmaker{}
+	started := make(chan startedMatch, 1)
+	book := newChallengeBook(hub, queue, func(a, b *matchTicket, variant string) error {
+		started <- startedMatch{black: a.playerID, white: b.playerID, variant: variant}
+		return nil
+	})
+	return hub, queue, book, started
+}
+
+// ロビーに接続したクライアントを登録する
+func connectClient(hub *lobbyHub, playerID string) *lobbyClient {
+	c := newLobbyClient(nil, playerID, playerID)
+	hub.register <- c
+	return c
+}
+
+// 指定した種類のメッセージが届くまで読み進める
+func expectMessage(t *testing.T, c *lobbyClient, kind string) map[string]interface{} {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case msg := <-c.send:
+			if m, ok := msg.(map[string]interface{}); ok && m["type"] == kind {
+				return m
+			}
+		case <-timeout:
+			t.Fatalf("Expected %s for %s", kind, c.playerID)
+			return nil
+		}
+	}
+}
+
+func Test01_ChallengeSelf(t *testing.T) {
+	hub, _, book, _ := newChallengeFixture()
+	alice := connectClient(hub, "alice")
+
+	if _, err := book.open(alice, "alice", "", ""); !errors.Is(err, errChallengeSelf) {
+		t.Errorf("Expected errChallengeSelf, got %v", err)
+	}
+}
+
+func Test02_ChallengeBusyPlayers(t *testing.T) {
+	hub, _, book, started := newChallengeFixture()
+	alice := connectClient(hub, "alice")
+	bob := connectClient(hub, "bob")
+	connectClient(hub, "carol")
+
+	// 対局中の相手には挑戦できない
+	hub.enterRoom("carol", "carol", presencePlaying)
+	if _, err := book.open(alice, "carol", "", ""); !errors.Is(err, errPlayerUnavailable) {
+		t.Errorf("Expected busy target to be unavailable, got %v", err)
+	}
+	// 観戦中の挑戦者も挑戦できない
+	hub.enterRoom("alice", "alice", presenceSpectating)
+	if _, err := book.open(alice, "bob", "", ""); !errors.Is(err, errPlayerUnavailable) {
+		t.Errorf("Expected busy challenger to be rejected, got %v", err)
+	}
+	hub.leaveRoom("alice", presenceSpectating)
+
+	// 通知後に挑戦者が対局を始めていれば承諾しても対局にならない
+	c, err := book.open(alice, "bob", "", "")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	hub.enterRoom("alice", "alice", presencePlaying)
+	if err := book.answer(bob, c.id, true); !errors.Is(err, errPlayerUnavailable) {
+		t.Errorf("Expected accept to fail for a busy challenger, got %v", err)
+	}
+	if m := expectMessage(t, bob, "challenge_resolved"); m["result"] != "unavailable" {
+		t.Errorf("Expected result unavailable, got %v", m["result"])
+	}
+	select {
+	case m := <-started:
+		t.Errorf("Expected no match, got %+v", m)
+	default:
+	}
+}
+
+func Test03_ChallengeExpires(t *testing.T) {
+	defer func(timeout time.Duration) { ChallengeTimeout = timeout }(ChallengeTimeout)
+	ChallengeTimeout = 20 * time.Millisecond
+
+	hub, _, book, _ := newChallengeFixture()
+	alice := connectClient(hub, "alice")
+	bob := connectClient(hub, "bob")
+
+	c, err := book.open(alice, "bob", "", "")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	for _, client := range []*lobbyClient{alice, bob} {
+		if m := expectMessage(t, client, "challenge_resolved"); m["result"] != "expired" {
+			t.Errorf("Expected result expired for %s, got %v", client.playerID, m["result"])
+		}
+	}
+	if err := book.answer(bob, c.id, true); !errors.Is(err, errNoSuchChallenge) {
+		t.Errorf("Expected expired challenge to be gone, got %v", err)
+	}
+}
+
+func Test04_ChallengeDeclined(t *testing.T) {
+	hub, _, book, started := newChallengeFixture()
+	alice := connectClient(hub, "alice")
+	bob := connectClient(hub, "bob")
+
+	c, err := book.open(alice, "bob", "", "")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	expectMessage(t, bob, "challenge_received")
+
+	// 挑戦された側以外は応答できない
+	if err := book.answer(alice, c.id, true); !errors.Is(err, errNoSuchChallenge) {
+		t.Errorf("Expected challenger to be unable to answer, got %v", err)
+	}
+	if err := book.answer(bob, c.id, false); err != nil {
+		t.Fatalf("decline failed: %v", err)
+	}
+	if m := expectMessage(t, alice, "challenge_resolved"); m["result"] != "declined" {
+		t.Errorf("Expected result declined, got %v", m["result"])
+	}
+	select {
+	case m := <-started:
+		t.Errorf("Expected no match, got %+v", m)
+	default:
+	}
+}
+
+func Test05_ChallengeCancelledOnDisconnect(t *testing.T) {
+	hub, _, book, _ := newChallengeFixture()
+	alice := connectClient(hub, "alice")
+	bob := connectClient(hub, "bob")
+
+	c, err := book.open(alice, "bob", "", "")
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	book.removeClient(alice)
+
+	if m := expectMessage(t, bob, "challenge_resolved"); m["result"] != "cancelled" {
+		t.Errorf("Expected result cancelled, got %v", m["result"])
+	}
+	if err := book.answer(bob, c.id, true); !errors.Is(err, errNoSuchChallenge) {
+		t.Errorf("Expected cancelled challenge to be gone, got %v", err)
+	}
+}
+
+func Test06_ChallengeAcceptedLeavesQueue(t *testing.T) {
+	hub, queue, book, started := newChallengeFixture()
+	alice := connectClient(hub, "alice")
+	bob := connectClient(hub, "bob")
+
+	// マッチング待ちのまま挑戦し、承諾されたら待ちを外す
+	queue.enqueue(&matchTicket{playerID: "alice", client: alice, joinedAt: time.Now()})
+	hub.setQueued("alice", true)
+
+	c, err := book.open(alice, "bob", "", challengeColorBlack)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if err := book.answer(bob, c.id, true); err != nil {
+		t.Fatalf("accept failed: %v", err)
+	}
+	select {
+	case m := <-started:
+		if m.black != "alice" || m.white != "bob" {
+			t.Errorf("Expected alice as black, got %+v", m)
+		}
+	default:
+		t.Fatal("Expected match to start")
+	}
+	if queue.cancel("alice") {
+		t.Error("Expected challenger to be removed from the queue")
+	}
+	if status := hub.statusOf("alice"); status != presenceIdle {
+		t.Errorf("Expected alice to be idle before joining the room, got %s", status)
+	}
+}
@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/chat"
+	"be-binareversi/model"
+	"log"
+	"time"
+)
+
+// チャットのチャンネル
+const (
+	chatChannelLobby      = "lobby"
+	chatChannelPlayers    = "players"
+	chatChannelSpectators = "spectators"
+)
+
+// ルームごとに保持するチャット履歴の件数
+const chatHistorySize = 100
+
+// 発言内容に適用するフィルタ（差し替え可能）
+var ChatFilter chat.Filter = chat.NewWordListFilter()
+
+// 発言をDBにも保存するかどうか
+var ChatPersist = false
+
+// プレイヤーごとの発言回数制限（10秒に5回まで）
+var chatLimiter = chat.NewRateLimiter(5, 10*time.Second)
+
+var lobbyChat = chat.NewHistory(chatHistorySize)
+
+// 発言を検証・フィルタして履歴に追加する
+// @param history 追加先の履歴
+// @param roomID ルームID（ロビーなら空）
+// @param channel チャンネル
+// @param playerID 発言者
+// @param text 発言内容
+// @return chat.Message 追加された発言
+// @return error 検証・制限・フィルタに引っかかった場合はエラー
+func postChat(history *chat.History, roomID, channel, playerID, text string) (chat.Message, error) {
+	text, err := chat.Normalize(text)
+	if err != nil {
+		return chat.Message{}, err
+	}
+	if !chatLimiter.Allow(playerID, time.Now()) {
+		return chat.Message{}, chat.ErrRateLimited
+	}
+	if text, err = ChatFilter.Apply(text); err != nil {
+		return chat.Message{}, err
+	}
+
+	msg := history.Add(chat.Message{
+		Channel:  channel,
+		PlayerID: playerID,
+		Name:     playerName(playerID),
+		Text:     text,
+		SentAt:   time.Now(),
+	})
+
+	if ChatPersist {
+		if err := db.CreateChatMessage(&model.ChatMessage{
+			RoomID:   roomID,
+			Channel:  channel,
+			PlayerID: playerID,
+			Text:     text,
+		}); err != nil {
+			log.Println("Failed to save chat message:", err)
+		}
+	}
+	return msg, nil
+}
+
+// 保存済みの発言を読み込んで履歴に戻す（再起動後も直近の会話を表示するため）
+// @param roomID ルームID（ロビーなら空）
+// @param histories チャンネルごとの読み込み先
+func restoreChat(roomID string, histories map[string]*chat.History) {
+	if !ChatPersist {
+		return
+	}
+	// ルームでは複数のチャンネルが混ざるので、チャンネル数ぶん多めに読む
+	msgs, err := db.GetChatMessages(roomID, chatHistorySize*len(histories))
+	if err != nil {
+		log.Println("Failed to load chat messages:", err)
+		return
+	}
+	for _, m := range msgs {
+		if history, ok := histories[m.Channel]; ok {
+			history.Add(chat.Message{
+				Channel:  m.Channel,
+				PlayerID: m.PlayerID,
+				Name:     playerName(m.PlayerID),
+				Text:     m.Text,
+				SentAt:   m.CreatedAt,
+			})
+		}
+	}
+}
+
+// ロビーのチャット履歴を保存済みの発言から復元する（起動時に一度呼ぶ）
+func RestoreLobbyChat() {
+	restoreChat("", map[string]*chat.History{chatChannelLobby: lobbyChat})
+}
@@ -0,0 +1,44 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/chat"
+	"be-binareversi/model"
+	"be-binareversi/repository"
+	"testing"
+)
+
+func Test12_RestoreChatSplitsChannels(t *testing.T) {
+	defer db.SetRepositories(repository.Repositories{
+		Players: db.Players, Rooms: db.Rooms, Games: db.Games,
+		Sessions: db.Sessions, Ratings: db.Ratings, Chats: db.Chats,
+	})
+	db.SetRepositories(repository.NewMemory())
+	defer func(persist bool) { ChatPersist = persist }(ChatPersist)
+	ChatPersist = true
+
+	for _, m := range []model.ChatMessage{
+		{RoomID: "room", Channel: chatChannelPlayers, PlayerID: "alice", Text: "hi"},
+		{RoomID: "room", Channel: chatChannelSpectators, PlayerID: "carol", Text: "gl"},
+		{RoomID: "other", Channel: chatChannelPlayers, PlayerID: "bob", Text: "elsewhere"},
+		{RoomID: "room", Channel: chatChannelPlayers, PlayerID: "bob", Text: "hello"},
+	} {
+		m := m
+		if err := db.CreateChatMessage(&m); err != nil {
+			t.Fatalf("CreateChatMessage failed: %v", err)
+		}
+	}
+
+	// 再起動後に作り直したルームでも、保存済みの発言がチャンネルごとに戻る
+	players, spectators := chat.NewHistory(chatHistorySize), chat.NewHistory(chatHistorySize)
+	restoreChat("room", map[string]*chat.History{
+		chatChannelPlayers:    players,
+		chatChannelSpectators: spectators,
+	})
+	if list := players.List(); len(list) != 2 || list[0].Text != "hi" || list[1].Text != "hello" {
+		t.Errorf("Unexpected player chat: %+v", list)
+	}
+	if list := spectators.List(); len(list) != 1 || list[0].Text != "gl" {
+		t.Errorf("Unexpected spectator chat: %+v", list)
+	}
+}
@@ -2,8 +2,7 @@ package websocket
 
 import (
 	"be-binareversi/db"
-	"be-binareversi/libs/bitop"
-	"be-binareversi/libs/reversi"
+	"be-binareversi/service/lobby"
 	"encoding/json"
 	"log"
 	"net/http"
@@ -12,13 +11,6 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-var gameClients = make(map[string]map[*websocket.Conn]string)
-var gameInstances = make(map[string]*reversi.Game)
-var playerColors = make(map[string]map[string]int)
-var playerPassCounts = make(map[string]map[string]int)
-var lastPassPlayer = make(map[string]string)
-var playerOperatorCounts = make(map[string]map[string]map[string]int)
-
 func HandleGame(roomID string, playerID string, w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -33,301 +25,308 @@ func HandleGame(roomID string, playerID string, w http.ResponseWriter, r *http.R
 	}
 	defer conn.Close()
 
-	room, err := db.GetRoomByID(roomID)
+	room, err := Rooms.Room(roomID)
 	if err != nil {
 		conn.WriteJSON(map[string]string{"error": "room not found"})
 		return
 	}
 
+	gr := getGameRoom(room)
+
+	// 着席していない登録済みプレイヤーは、観戦を許可したルームに限り観戦者として扱う
 	if playerID != room.Player1 && (room.Player2 == nil || playerID != *room.Player2) {
-		conn.WriteControl(websocket.CloseMessage,
-			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "unauthorized player"),
-			time.Now().Add(time.Second),
-		)
+		if _, err := db.GetPlayerByID(playerID); err != nil || !lobby.CanSpectate(room) {
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, "unauthorized player"),
+				time.Now().Add(time.Second),
+			)
+			return
+		}
+		lobbyClients.enterRoom(playerID, playerName(playerID), presenceSpectating)
+		defer lobbyClients.leaveRoom(playerID, presenceSpectating)
+		handleSpectator(gr, conn, playerID)
 		return
 	}
 
-	if gameClients[roomID] == nil {
-		gameClients[roomID] = make(map[*websocket.Conn]string)
-	}
-	gameClients[roomID][conn] = playerID
+	gr.seat(room)
+	lobbyClients.enterRoom(playerID, playerName(playerID), presencePlaying)
+	defer lobbyClients.leaveRoom(playerID, presencePlaying)
 
-	if _, ok := gameInstances[roomID]; !ok {
-		gameInstances[roomID] = reversi.NewGame(roomID)
-	}
-	game := gameInstances[roomID]
-
-	if _, ok := playerColors[roomID]; !ok {
-		playerColors[roomID] = make(map[string]int)
-		playerColors[roomID][room.Player1] = reversi.Black
-		if room.Player2 != nil {
-			playerColors[roomID][*room.Player2] = reversi.White
-		}
-	}
-	playerColor := playerColors[roomID][playerID]
+	gr.attach(conn, playerID)
+	defer gr.detach(conn)
 
 	for {
 		_, reader, err := conn.NextReader()
 		if err != nil {
-			delete(gameClients[roomID], conn)
 			break
 		}
 
 		var msg map[string]interface{}
 		if err := json.NewDecoder(reader).Decode(&msg); err != nil {
+			gr.mu.Lock()
 			conn.WriteJSON(map[string]string{"error": "invalid JSON"})
+			gr.mu.Unlock()
 			continue
 		}
 
-		typeVal, ok := msg["type"].(string)
-		if !ok {
-			conn.WriteJSON(map[string]string{"error": "missing or invalid type"})
-			continue
-		}
+		gr.mu.Lock()
+		handleGameMessage(gr, conn, playerID, msg)
+		gr.mu.Unlock()
+	}
+}
 
-		switch typeVal {
-		case "join":
-			var boardToSend [8][8]int
-			if game.GetTurn() == playerColor {
-				boardToSend = game.GetBoardWithValidMoves(playerColor)
-			} else {
-				boardToSend = game.GetBoard()
-			}
+// 対局メッセージを1件処理する（gr.mu を保持した状態で呼ぶ）
+func handleGameMessage(gr *gameRoom, conn *websocket.Conn, playerID string, msg map[string]interface{}) {
+	roomID := gr.id
+	game := gr.match.Game()
+	playerColor, _ := gr.match.Color(playerID)
 
-			conn.WriteJSON(map[string]interface{}{
-				"type":        "game_start",
-				"playerID":    playerID,
-				"yourColor":   playerColor,
-				"board":       boardToSend,
-				"currentTurn": (game.GetTurnCount() + 1) / 2,
-				"isYourTurn":  (game.GetTurn() == playerColor),
-			})
+	typeVal, ok := msg["type"].(string)
+	if !ok {
+		conn.WriteJSON(map[string]string{"error": "missing or invalid type"})
+		return
+	}
 
-		case "move":
-			game.IncrementTurnCount()
-			xRaw, xOk := msg["x"].(float64)
-			yRaw, yOk := msg["y"].(float64)
-			if !xOk || !yOk {
-				conn.WriteJSON(map[string]string{"error": "invalid x or y"})
-				continue
-			}
-			x, y := int(xRaw), int(yRaw)
+	switch typeVal {
+	case "join":
+		sendGameStart(gr, conn, playerID, playerColor)
 
-			board, err := game.PlaceDisc(playerColor, x, y)
-			if err != nil {
-				conn.WriteJSON(map[string]string{"error": err.Error()})
-				continue
-			}
+	case "resume":
+		lastSeqRaw, ok := msg["lastSeq"].(float64)
+		if !ok {
+			conn.WriteJSON(map[string]string{"error": "invalid lastSeq"})
+			return
+		}
+		if !gr.replay(conn, playerID, int(lastSeqRaw)) {
+			// ログから溢れている場合は現在の状態を送り直す
+			sendGameStart(gr, conn, playerID, playerColor)
+		}
+		conn.WriteJSON(map[string]interface{}{
+			"type": "resumed",
+			"seq":  gr.seq,
+		})
+
+	case "move":
+		xRaw, xOk := msg["x"].(float64)
+		yRaw, yOk := msg["y"].(float64)
+		if !xOk || !yOk {
+			conn.WriteJSON(map[string]string{"error": "invalid x or y"})
+			return
+		}
+		if err := gr.playMove(playerID, int(xRaw), int(yRaw)); err != nil {
+			conn.WriteJSON(map[string]string{"error": err.Error()})
+		}
 
-			for c, pid := range gameClients[roomID] {
-				color := playerColors[roomID][pid]
-				var boardToSend [8][8]int
-				if game.GetTurn() == color {
-					boardToSend = game.GetBoardWithValidMoves(color)
-				} else {
-					boardToSend = board
-				}
-
-				c.WriteJSON(map[string]interface{}{
-					"type":        "board_update",
-					"board":       boardToSend,
-					"currentTurn": (game.GetTurnCount() + 1) / 2,
-					"isYourTurn":  (game.GetTurn() == color),
-				})
-			}
+	case "operation":
+		rowRaw, rowOk := msg["row"].(float64)
+		valueRaw, valueOk := msg["value"].(float64)
+		operator, opOk := msg["operator"].(string)
+		if !rowOk || !valueOk || !opOk {
+			conn.WriteJSON(map[string]string{"error": "missing or invalid operation parameters"})
+			return
+		}
+		if err := gr.playOperation(playerID, int(rowRaw), int(valueRaw), operator); err != nil {
+			conn.WriteJSON(map[string]string{"error": err.Error()})
+		}
 
-			if game.IsGameOver() {
-				broadcastToRoom(roomID, map[string]interface{}{
-					"type":   "game_over",
-					"winner": game.GetWinner(),
-				})
-			}
+	case "surrender":
+		gr.surrender(playerID)
 
-		case "operation":
-			game.IncrementTurnCount()
-			rowRaw, rowOk := msg["row"].(float64)
-			valueRaw, valueOk := msg["value"].(float64)
-			operator, opOk := msg["operator"].(string)
+	case "pass":
+		if err := gr.playPass(playerID); err != nil {
+			conn.WriteJSON(map[string]string{"error": err.Error()})
+		}
 
-			if playerOperatorCounts[roomID] == nil {
-				playerOperatorCounts[roomID] = make(map[string]map[string]int)
-			}
-			if playerOperatorCounts[roomID][playerID] == nil {
-				playerOperatorCounts[roomID][playerID] = map[string]int{"+": 0, "*": 0}
-			}
+	case "offer_draw", "request_takeback", "offer_rematch":
+		kinds := map[string]string{
+			"offer_draw":       requestDraw,
+			"request_takeback": requestTakeback,
+			"offer_rematch":    requestRematch,
+		}
+		if errMsg := gr.openRequest(playerID, kinds[typeVal]); errMsg != "" {
+			conn.WriteJSON(map[string]string{"error": errMsg})
+		}
 
-			if playerOperatorCounts[roomID][playerID][operator] >= 2 {
-				conn.WriteJSON(map[string]string{"error": "Operator " + operator + " used too many times (max 2)."})
-				continue
-			}
-			playerOperatorCounts[roomID][playerID][operator]++
+	case "request_response":
+		kind, kindOk := msg["kind"].(string)
+		accept, acceptOk := msg["accept"].(bool)
+		if !kindOk || !acceptOk {
+			conn.WriteJSON(map[string]string{"error": "invalid kind or accept"})
+			return
+		}
+		if errMsg := gr.answerRequest(playerID, kind, accept); errMsg != "" {
+			conn.WriteJSON(map[string]string{"error": errMsg})
+		}
 
-			if !rowOk || !valueOk || !opOk {
-				conn.WriteJSON(map[string]string{"error": "missing or invalid operation parameters"})
-				continue
-			}
-			rowIndex := int(rowRaw)
-			value := int(valueRaw)
+	case "chat":
+		text, _ := msg["text"].(string)
+		chatMsg, err := postChat(gr.playerChat, roomID, chatChannelPlayers, playerID, text)
+		if err != nil {
+			conn.WriteJSON(map[string]string{"error": err.Error()})
+			return
+		}
+		gr.sendChat(map[string]interface{}{
+			"type":    "chat",
+			"message": chatMsg,
+		})
+
+	case "get_chat_history":
+		conn.WriteJSON(map[string]interface{}{
+			"type":     "chat_history",
+			"channel":  chatChannelPlayers,
+			"messages": gr.playerChat.List(),
+		})
+
+	case "get_valid_moves":
+		moves := game.GetValidMovesMap(playerColor)
+		conn.WriteJSON(map[string]interface{}{
+			"type":      "valid_moves",
+			"moves_map": moves,
+		})
+
+	case "get_status":
+		status := gr.match.Status(playerID)
+		conn.WriteJSON(map[string]interface{}{
+			"type":           "status_info",
+			"remaining_plus": status.RemainingPlus,
+			"remaining_mul":  status.RemainingMul,
+			"remaining_pass": status.RemainingPass,
+		})
+
+	case "ready":
+		if errMsg := gr.markReady(playerID); errMsg != "" {
+			conn.WriteJSON(map[string]string{"error": errMsg})
+		}
+	case "exit_room":
+		if errMsg := gr.exit(playerID); errMsg != "" {
+			conn.WriteJSON(map[string]string{"error": errMsg})
+			return
+		}
+		conn.WriteJSON(map[string]interface{}{
+			"type":     "exited_room",
+			"roomID":   roomID,
+			"playerID": playerID,
+		})
+
+	case "kick":
+		kicked, err := Rooms.KickPlayer(playerID, roomID)
+		if err != nil {
+			conn.WriteJSON(map[string]string{"error": err.Error()})
+			return
+		}
+		gr.removePlayer(kicked, "kicked")
 
-			if rowIndex < 0 || rowIndex >= 8 {
-				conn.WriteJSON(map[string]string{"error": "row index out of bounds"})
-				continue
-			}
+	case "transfer_host":
+		room, err := Rooms.TransferHost(playerID, roomID)
+		if err != nil {
+			conn.WriteJSON(map[string]string{"error": err.Error()})
+			return
+		}
+		gr.broadcast(map[string]interface{}{
+			"type":   "host_changed",
+			"hostID": room.HostID,
+		})
+
+	case "close_room":
+		if err := Rooms.DeleteRoom(playerID, roomID); err != nil {
+			conn.WriteJSON(map[string]string{"error": err.Error()})
+			return
+		}
+		gr.broadcast(map[string]interface{}{
+			"type":   "room_closed",
+			"roomID": roomID,
+		})
+		removeGameRoom(roomID)
+
+	default:
+		conn.WriteJSON(map[string]string{"error": "unknown message type"})
+	}
+}
 
-			// 対象の行を取得し演算
-			row := game.GetBoard()[rowIndex]
-			newRow, err := bitop.ApplyBitOperation(row, value, operator)
-			if err != nil {
-				conn.WriteJSON(map[string]string{"error": err.Error()})
-				continue
-			}
+// 現在の盤面を game_start としてプレイヤーに送信する（gr.mu を保持した状態で呼ぶ）
+func sendGameStart(gr *gameRoom, conn *websocket.Conn, playerID string, playerColor int) {
+	game := gr.match.Game()
+	var boardToSend [][]int
+	if game.GetTurn() == playerColor {
+		boardToSend = game.GetBoardWithValidMoves(playerColor)
+	} else {
+		boardToSend = game.GetBoard()
+	}
 
-			// 盤面の更新
-			newBoard := game.GetBoard()
-			newBoard[rowIndex] = newRow
-			game.SetBoard(newBoard)
-			game.PassTurn()
-
-			// 全クライアントに board_update を送信
-			for c, pid := range gameClients[roomID] {
-				color := playerColors[roomID][pid]
-				var boardToSend [8][8]int
-				if game.GetTurn() == color {
-					boardToSend = game.GetBoardWithValidMoves(color)
-				} else {
-					boardToSend = game.GetBoard()
-				}
-
-				c.WriteJSON(map[string]interface{}{
-					"type":        "board_update",
-					"board":       boardToSend,
-					"currentTurn": (game.GetTurnCount() + 1) / 2,
-					"isYourTurn":  (game.GetTurn() == color),
-				})
-			}
+	payload := map[string]interface{}{
+		"type":        "game_start",
+		"playerID":    playerID,
+		"yourColor":   playerColor,
+		"board":       boardToSend,
+		"boardSize":   game.Size,
+		"currentTurn": (game.GetTurnCount() + 1) / 2,
+		"isYourTurn":  (game.GetTurn() == playerColor),
+		"seq":         gr.seq,
+		"state":       gr.state,
+	}
+	if gr.clock != nil {
+		payload["clock"] = gr.clockState()
+	}
+	conn.WriteJSON(payload)
+}
 
-		case "surrender":
-			// 通知: surrender したプレイヤーが敗北
-			var winner int
-			if playerColor == reversi.Black {
-				winner = reversi.White
-			} else {
-				winner = reversi.Black
-			}
+// 観戦者の接続を処理する
+func handleSpectator(gr *gameRoom, conn *websocket.Conn, playerID string) {
+	gr.attachSpectator(conn, playerID)
+	defer gr.detachSpectator(conn)
 
-			broadcastToRoom(roomID, map[string]interface{}{
-				"type":   "game_over",
-				"winner": winner,
-			})
+	for {
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			break
+		}
 
-		case "pass":
-			if playerPassCounts[roomID] == nil {
-				playerPassCounts[roomID] = make(map[string]int)
+		gr.mu.Lock()
+		switch msg["type"] {
+		case "join":
+			payload := map[string]interface{}{
+				"type":        "spectate_start",
+				"playerID":    playerID,
+				"board":       gr.match.Game().GetBoard(),
+				"boardSize":   gr.match.Game().Size,
+				"currentTurn": (gr.match.Game().GetTurnCount() + 1) / 2,
+				"turn":        gr.match.Game().GetTurn(),
+				"players":     gr.match.Players(),
+				"state":       gr.state,
 			}
-			playerPassCounts[roomID][playerID]++
-
-			if playerPassCounts[roomID][playerID] > 3 {
-				conn.WriteJSON(map[string]string{
-					"error": "You have exceeded the maximum number of passes (3).",
-				})
-				playerPassCounts[roomID][playerID] = 3 // 上限固定
-				continue
+			if gr.clock != nil {
+				payload["clock"] = gr.clockState()
 			}
+			conn.WriteJSON(payload)
 
-			// 連続パス判定
-			if lastPassPlayer[roomID] != "" && lastPassPlayer[roomID] != playerID {
-				// 2人連続でパスされた → 勝者判定
-				board := game.GetBoard()
-				blackCount, whiteCount := 0, 0
-				for _, row := range board {
-					for _, cell := range row {
-						if cell == reversi.Black {
-							blackCount++
-						} else if cell == reversi.White {
-							whiteCount++
-						}
-					}
-				}
-				winner := -1
-				if blackCount > whiteCount {
-					winner = reversi.Black
-				} else if whiteCount > blackCount {
-					winner = reversi.White
-				}
-				broadcastToRoom(roomID, map[string]interface{}{
-					"type":   "game_over",
-					"winner": winner,
-				})
-			} else {
-				game.IncrementTurnCount()
-				// 手番変更、通知
-				game.PassTurn()
-				lastPassPlayer[roomID] = playerID
-
-				for c, pid := range gameClients[roomID] {
-					color := playerColors[roomID][pid]
-					var boardToSend [8][8]int
-					if game.GetTurn() == color {
-						boardToSend = game.GetBoardWithValidMoves(color)
-					} else {
-						boardToSend = game.GetBoard()
-					}
-
-					c.WriteJSON(map[string]interface{}{
-						"type":        "board_update",
-						"board":       boardToSend,
-						"currentTurn": (game.GetTurnCount() + 1) / 2,
-						"isYourTurn":  (game.GetTurn() == color),
-					})
-				}
+		case "chat":
+			// 観戦者の発言は対局者には届けない
+			text, _ := msg["text"].(string)
+			chatMsg, err := postChat(gr.spectatorChat, gr.id, chatChannelSpectators, playerID, text)
+			if err != nil {
+				conn.WriteJSON(map[string]string{"error": err.Error()})
+				break
 			}
-
-		case "get_valid_moves":
-			moves := game.GetValidMovesMap(playerColor)
-			conn.WriteJSON(map[string]interface{}{
-				"type":      "valid_moves",
-				"moves_map": moves,
+			gr.sendSpectators(map[string]interface{}{
+				"type":    "chat",
+				"message": chatMsg,
 			})
 
-		case "get_status":
-			plusCount := 0
-			mulCount := 0
-			passCount := 0
-
-			if playerOperatorCounts[roomID] != nil && playerOperatorCounts[roomID][playerID] != nil {
-				plusCount = playerOperatorCounts[roomID][playerID]["+"]
-				mulCount = playerOperatorCounts[roomID][playerID]["*"]
-			}
-			if playerPassCounts[roomID] != nil {
-				passCount = playerPassCounts[roomID][playerID]
-			}
-
+		case "get_chat_history":
 			conn.WriteJSON(map[string]interface{}{
-				"type":           "status_info",
-				"remaining_plus": 2 - plusCount,
-				"remaining_mul":  2 - mulCount,
-				"remaining_pass": 3 - passCount,
+				"type":     "chat_history",
+				"channel":  chatChannelPlayers,
+				"messages": gr.playerChat.List(),
 			})
-
-		case "exit_room":
-			db.DeleteRoom(roomID) //ルームの削除
 			conn.WriteJSON(map[string]interface{}{
-				"type":     "exited_room",
-				"roomID":   roomID,
-				"playerID": playerID,
+				"type":     "chat_history",
+				"channel":  chatChannelSpectators,
+				"messages": gr.spectatorChat.List(),
 			})
 
 		default:
-			conn.WriteJSON(map[string]string{"error": "unknown message type"})
-		}
-	}
-}
-
-func broadcastToRoom(roomID string, msg interface{}) {
-	for conn := range gameClients[roomID] {
-		if err := conn.WriteJSON(msg); err != nil {
-			conn.Close()
-			delete(gameClients[roomID], conn)
+			conn.WriteJSON(map[string]string{"error": "spectators cannot play"})
 		}
+		gr.mu.Unlock()
 	}
 }
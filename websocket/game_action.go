@@ -0,0 +1,127 @@
+package websocket
+
+import (
+	gamesvc "be-binareversi/service/game"
+	"errors"
+	"time"
+)
+
+// AIが着手するまでの待ち時間
+const botMoveDelay = 700 * time.Millisecond
+
+var errGameOver = errors.New("game is over")
+
+// 石を置く（mu を保持した状態で呼ぶ）
+// @param playerID 着手したプレイヤー
+// @param x X座標
+// @param y Y座標
+// @return error 不正な手であればエラー
+func (gr *gameRoom) playMove(playerID string, x, y int) error {
+	if err := gr.checkPlayable(); err != nil {
+		return err
+	}
+	before := gr.match.Snapshot()
+	result, err := gr.match.Move(playerID, x, y)
+	if err != nil {
+		return err
+	}
+	gr.apply(result, before)
+	return nil
+}
+
+// 行に演算を適用する（mu を保持した状態で呼ぶ）
+// @param playerID 演算したプレイヤー
+// @param rowIndex 対象の行
+// @param value 演算対象値
+// @param operator "+" または "*"
+// @return error 不正な演算であればエラー
+func (gr *gameRoom) playOperation(playerID string, rowIndex, value int, operator string) error {
+	if err := gr.checkPlayable(); err != nil {
+		return err
+	}
+	before := gr.match.Snapshot()
+	result, err := gr.match.Operate(playerID, rowIndex, value, operator)
+	if err != nil {
+		return err
+	}
+	gr.apply(result, before)
+	return nil
+}
+
+// パスする（mu を保持した状態で呼ぶ）
+// @param playerID パスしたプレイヤー
+// @return error パス回数の上限を超えていればエラー
+func (gr *gameRoom) playPass(playerID string) error {
+	if err := gr.checkPlayable(); err != nil {
+		return err
+	}
+	before := gr.match.Snapshot()
+	result, err := gr.match.Pass(playerID)
+	if err != nil {
+		return err
+	}
+	gr.apply(result, before)
+	return nil
+}
+
+// 投了する（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) surrender(playerID string) {
+	if !gr.playing() {
+		return
+	}
+	if result, err := gr.match.Surrender(playerID); err == nil {
+		gr.finish(result.Winner, result.Reason)
+	}
+}
+
+// アクションの結果を時計・履歴に反映し、通知する（mu を保持した状態で呼ぶ）
+// @param result 対局ルールが返した結果
+// @param before アクション直前の局面
+func (gr *gameRoom) apply(result *gamesvc.Result, before gamesvc.Snapshot) {
+	// 連続パスによる終局は手番を進めずにそのまま終了する
+	if result.Over && result.Reason == gamesvc.ReasonDoublePass {
+		gr.finish(result.Winner, result.Reason)
+		return
+	}
+
+	gr.endTurn(result.PlayerID, result.Action, before)
+	if !gr.playing() {
+		return
+	}
+	gr.broadcastBoard()
+
+	if result.Over {
+		gr.finish(result.Winner, result.Reason)
+	}
+}
+
+// AIの手番であれば着手を予約する（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) scheduleBot() {
+	if !gr.playing() {
+		return
+	}
+	game := gr.match.Game()
+	for pid, color := range gr.match.Players() {
+		if !gr.bots[pid] || color != game.GetTurn() {
+			continue
+		}
+		botID := pid
+		turnCount := game.GetTurnCount()
+		time.AfterFunc(botMoveDelay, func() {
+			gr.mu.Lock()
+			defer gr.mu.Unlock()
+			// 待っている間に局面が変わっていれば（再戦で盤面が作り直された場合も含む）何もしない
+			game := gr.match.Game()
+			color, _ := gr.match.Color(botID)
+			if !gr.playing() || game.GetTurnCount() != turnCount || color != game.GetTurn() {
+				return
+			}
+			if move, ok := game.BestMove(color); ok {
+				gr.playMove(botID, move.X, move.Y)
+			} else if err := gr.playPass(botID); err != nil {
+				gr.surrender(botID)
+			}
+		})
+		return
+	}
+}
@@ -0,0 +1,50 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	gamesvc "be-binareversi/service/game"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// 終了時点の対局記録を作成する（mu を保持した状態で呼ぶ）
+// @param winner 勝者（Black=1, White=0, 引き分け=-1）
+// @param reason 終了理由
+// @return *model.GameRecord 対局記録（着席者が揃っていなければ nil）
+func (gr *gameRoom) buildRecord(winner int, reason string) *model.GameRecord {
+	blackID, whiteID := gr.match.PlayerOf(reversi.Black), gr.match.PlayerOf(reversi.White)
+	if blackID == "" || whiteID == "" {
+		return nil
+	}
+
+	blackDiscs, whiteDiscs := gr.match.Game().CountDiscs()
+	actions, _ := json.Marshal(gr.actions)
+	return &model.GameRecord{
+		RoomID:        gr.id,
+		BlackID:       blackID,
+		WhiteID:       whiteID,
+		Winner:        winner,
+		Reason:        reason,
+		BlackDiscs:    blackDiscs,
+		WhiteDiscs:    whiteDiscs,
+		BlackPlus:     gr.match.OperatorUses(blackID, gamesvc.OperatorPlus),
+		BlackMul:      gr.match.OperatorUses(blackID, gamesvc.OperatorMul),
+		WhitePlus:     gr.match.OperatorUses(whiteID, gamesvc.OperatorPlus),
+		WhiteMul:      gr.match.OperatorUses(whiteID, gamesvc.OperatorMul),
+		Actions:       string(actions),
+		StartPosition: gr.startPosition,
+		StartedAt:     gr.startedAt,
+		EndedAt:       time.Now(),
+	}
+}
+
+// 対局記録を保存し、レーティングを更新する
+func recordGameResult(record *model.GameRecord) {
+	if err := db.CreateGameRecord(record); err != nil {
+		log.Println("Failed to save game record:", err)
+	}
+	recordRatings(record.RoomID, record.BlackID, record.WhiteID, record.Winner)
+}
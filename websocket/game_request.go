@@ -0,0 +1,191 @@
+package websocket
+
+import (
+	"be-binareversi/libs/clock"
+	"be-binareversi/service/lobby"
+	"log"
+	"time"
+)
+
+// 引き分け提案・待った・再戦の申し込みが失効するまでの時間
+var RequestTimeout = 30 * time.Second
+
+// 申し込みの種類
+const (
+	requestDraw     = "draw"
+	requestTakeback = "takeback"
+	requestRematch  = "rematch"
+)
+
+// 相手の応答待ちになっている申し込み
+type pendingRequest struct {
+	Kind  string
+	From  string
+	timer *time.Timer
+}
+
+// 申し込みを受け付けて相手に通知する（mu を保持した状態で呼ぶ）
+// @param playerID 申し込んだプレイヤー
+// @param kind 申し込みの種類
+// @return string 受け付けられなければエラーメッセージ
+func (gr *gameRoom) openRequest(playerID, kind string) string {
+	opponent := gr.match.Opponent(playerID)
+	if opponent == "" {
+		return "no opponent"
+	}
+	if gr.pending != nil {
+		return "another request is pending"
+	}
+
+	switch kind {
+	case requestDraw:
+		if !gr.playing() {
+			return "game is not in progress"
+		}
+	case requestTakeback:
+		if !gr.playing() {
+			return "game is not in progress"
+		}
+		if n := len(gr.actions); n == 0 || gr.actions[n-1].PlayerID != playerID {
+			return "nothing to take back"
+		}
+	case requestRematch:
+		if gr.state != lobby.StatusFinished {
+			return "game is not over"
+		}
+	}
+
+	req := &pendingRequest{Kind: kind, From: playerID}
+	req.timer = time.AfterFunc(RequestTimeout, func() {
+		gr.mu.Lock()
+		defer gr.mu.Unlock()
+		if gr.pending == req {
+			gr.resolveRequest("expired")
+		}
+	})
+	gr.pending = req
+
+	gr.broadcast(map[string]interface{}{
+		"type":             "request_offered",
+		"kind":             kind,
+		"from":             playerID,
+		"expiresInSeconds": int(RequestTimeout / time.Second),
+	})
+	return ""
+}
+
+// 申し込みに応答する（mu を保持した状態で呼ぶ）
+// @param playerID 応答したプレイヤー
+// @param kind 応答対象の申し込みの種類
+// @param accept 承諾なら true
+// @return string 応答できなければエラーメッセージ
+func (gr *gameRoom) answerRequest(playerID, kind string, accept bool) string {
+	req := gr.pending
+	if req == nil || req.Kind != kind {
+		return "no such request"
+	}
+	if req.From == playerID {
+		return "cannot answer your own request"
+	}
+	if !accept {
+		gr.resolveRequest("declined")
+		return ""
+	}
+
+	if kind == requestDraw || kind == requestTakeback {
+		// 応答を待つ間に時間切れになっていれば、受け入れる前に対局を終える
+		if err := gr.checkPlayable(); err != nil {
+			return err.Error()
+		}
+	}
+
+	gr.resolveRequest("accepted")
+	switch kind {
+	case requestDraw:
+		gr.finish(-1, "draw_agreed")
+	case requestTakeback:
+		gr.takeBack()
+	case requestRematch:
+		gr.rematch()
+	}
+	return ""
+}
+
+// 保留中の申し込みを取り消す（mu を保持した状態で呼ぶ）
+// @param kinds 取り消し対象の種類（空なら全て）
+func (gr *gameRoom) cancelRequest(kinds ...string) {
+	if gr.pending == nil {
+		return
+	}
+	if len(kinds) > 0 {
+		match := false
+		for _, k := range kinds {
+			if gr.pending.Kind == k {
+				match = true
+			}
+		}
+		if !match {
+			return
+		}
+	}
+	gr.resolveRequest("cancelled")
+}
+
+// 保留中の申し込みを結果付きで閉じる（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) resolveRequest(result string) {
+	req := gr.pending
+	gr.pending = nil
+	req.timer.Stop()
+	gr.broadcast(map[string]interface{}{
+		"type":   "request_resolved",
+		"kind":   req.Kind,
+		"from":   req.From,
+		"result": result,
+	})
+}
+
+// 直前の手を取り消し、手番を戻す（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) takeBack() {
+	n := len(gr.history)
+	if n == 0 {
+		return
+	}
+	gr.match.Restore(gr.history[n-1])
+	clockBefore := gr.clocks[n-1]
+	gr.history = gr.history[:n-1]
+	gr.clocks = gr.clocks[:n-1]
+	gr.actions = gr.actions[:len(gr.actions)-1]
+
+	// 時計は取り消した手の手番開始時点に戻す（加算や1手ごとの再設定を相手側に与えない）
+	now := time.Now()
+	gr.turnStartedAt = now
+	if gr.clock != nil && gr.clock.Running() {
+		gr.clock.Restore(clockBefore, gr.match.Game().GetTurn(), now)
+		gr.scheduleFlag()
+	}
+	gr.broadcastBoard()
+}
+
+// 色を入れ替えて同じルームで再戦を始める（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) rematch() {
+	gr.match.Rematch()
+	gr.prepare()
+	gr.history = nil
+	gr.clocks = nil
+	gr.actions = nil
+	gr.clock = clock.New(gr.control)
+	gr.startedAt = time.Now()
+	if err := gr.transition(lobby.StatusInProgress); err != nil {
+		log.Println("Failed to start rematch:", err)
+		return
+	}
+
+	for pid, color := range gr.match.Players() {
+		gr.emit(pid, map[string]interface{}{
+			"type":      "rematch_start",
+			"yourColor": color,
+		})
+	}
+	gr.startClock()
+	gr.broadcastBoard()
+}
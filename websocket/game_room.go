@@ -0,0 +1,537 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/chat"
+	"be-binareversi/libs/clock"
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	gamesvc "be-binareversi/service/game"
+	"be-binareversi/service/lobby"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 切断後、不戦敗になるまでの猶予時間
+var ReconnectGracePeriod = 60 * time.Second
+
+// ルームごとに保持するイベントログの最大件数
+const maxEventLog = 256
+
+// ルームで発生したイベント（再接続時の再送に使う）
+type gameEvent struct {
+	Seq      int                    // 通し番号（1始まり）
+	PlayerID string                 // 送信対象のプレイヤー（空なら全員）
+	Payload  map[string]interface{} // 送信内容（seq を含む）
+}
+
+// 1手ごとの消費時間の記録
+type actionRecord struct {
+	PlayerID string        `json:"playerID"`
+	Action   string        `json:"action"` // move / operation / pass
+	Used     time.Duration `json:"-"`
+	UsedMs   int64         `json:"timeUsedMs"`
+}
+
+// gameRoom は1つの対局ルームの状態をまとめて管理するハブ
+type gameRoom struct {
+	mu            sync.Mutex
+	id            string
+	match         gamesvc.Match // 盤面・着席・パスや演算の回数
+	clients       map[*websocket.Conn]string
+	spectators    map[*websocket.Conn]string
+	bots          map[string]bool // サーバー側で着手するAIプレイヤー
+	events        []gameEvent
+	seq           int
+	graceTimers   map[string]*time.Timer
+	state         string          // ルームの進行状況（lobby.Status*）
+	ready         map[string]bool // 対局開始前に準備完了したプレイヤー
+	control       clock.Control
+	clock         *clock.Clock // 時間制限なしなら nil
+	flagTimer     *time.Timer
+	turnStartedAt time.Time
+	startedAt     time.Time
+	startPosition string // 標準以外の開始局面（対局記録用の局面文字列）
+	actions       []actionRecord
+	history       []gamesvc.Snapshot // actions と同じ長さで、各手の直前の局面を持つ
+	clocks        []clock.State      // history と同じ長さで、各手の直前の時計を持つ
+	pending       *pendingRequest
+	playerChat    *chat.History
+	spectatorChat *chat.History
+	reported      lobby.Score // 最後にロビーへ通知した石数
+}
+
+var gameRooms = make(map[string]*gameRoom)
+var gameRoomsMu sync.Mutex
+
+// ルームに対応するハブを取得（なければ作成）
+func getGameRoom(room *model.Room) *gameRoom {
+	gameRoomsMu.Lock()
+	defer gameRoomsMu.Unlock()
+
+	gr, ok := gameRooms[room.ID]
+	if !ok {
+		if room.Status == lobby.StatusInProgress {
+			// 対局中と記録されていても対局は残っていない（再起動後など）ので、準備完了の確認からやり直す
+			if recovered, err := Rooms.RecoverRoom(room.ID); err == nil {
+				room.Status = recovered.Status
+			} else {
+				log.Println("Failed to recover room:", err)
+			}
+		}
+		gr = &gameRoom{
+			id:            room.ID,
+			match:         gamesvc.NewMatch(room.ID),
+			clients:       make(map[*websocket.Conn]string),
+			spectators:    make(map[*websocket.Conn]string),
+			bots:          make(map[string]bool),
+			graceTimers:   make(map[string]*time.Timer),
+			state:         room.Status,
+			ready:         make(map[string]bool),
+			control:       lobby.TimeControlOf(room),
+			clock:         clock.New(lobby.TimeControlOf(room)),
+			turnStartedAt: time.Now(),
+			startedAt:     time.Now(),
+			playerChat:    chat.NewHistory(chatHistorySize),
+			spectatorChat: chat.NewHistory(chatHistorySize),
+		}
+		// 開始局面は対局開始時に決まるので、それまでは盤面の大きさだけ合わせておく
+		gr.match.Reset(reversi.StandardSetup(lobby.BoardSizeOf(room)))
+		restoreChat(room.ID, map[string]*chat.History{
+			chatChannelPlayers:    gr.playerChat,
+			chatChannelSpectators: gr.spectatorChat,
+		})
+		gameRooms[room.ID] = gr
+	}
+	return gr
+}
+
+// ルームのプレイヤーを着席させ、開始前の状態をルームに合わせる
+// ここでの席（Player1=Black, Player2=White）は仮のもので、実際の手番は対局開始時・再戦時に assignColors が決める
+func (gr *gameRoom) seat(room *model.Room) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	defer gr.syncState(room)
+
+	seats := map[string]int{room.Player1: reversi.Black}
+	if room.Player2 != nil {
+		seats[*room.Player2] = reversi.White
+	}
+	for pid, color := range seats {
+		if !gr.match.Seat(pid, color) {
+			continue
+		}
+		if player, err := db.GetPlayerByID(pid); err == nil && player.IsBot {
+			// AIは常に準備完了とみなす
+			gr.bots[pid] = true
+			gr.ready[pid] = true
+		}
+	}
+}
+
+// ハブを破棄する
+func removeGameRoom(roomID string) {
+	gameRoomsMu.Lock()
+	defer gameRoomsMu.Unlock()
+	delete(gameRooms, roomID)
+}
+
+// プレイヤーが接続中かどうか（AIは常に接続中とみなす。mu を保持した状態で呼ぶ）
+func (gr *gameRoom) isConnected(playerID string) bool {
+	if gr.bots[playerID] {
+		return true
+	}
+	for _, pid := range gr.clients {
+		if pid == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// イベントをログに追加し、対象クライアントへ送信する（mu を保持した状態で呼ぶ）
+// @param playerID 送信対象（空文字なら全員）
+// @param payload 送信内容
+func (gr *gameRoom) emit(playerID string, payload map[string]interface{}) {
+	gr.seq++
+	payload["seq"] = gr.seq
+	gr.events = append(gr.events, gameEvent{Seq: gr.seq, PlayerID: playerID, Payload: payload})
+	if len(gr.events) > maxEventLog {
+		gr.events = gr.events[len(gr.events)-maxEventLog:]
+	}
+
+	for conn, pid := range gr.clients {
+		if playerID != "" && pid != playerID {
+			continue
+		}
+		if err := conn.WriteJSON(payload); err != nil {
+			conn.Close()
+			delete(gr.clients, conn)
+		}
+	}
+	if playerID == "" {
+		gr.sendSpectators(payload)
+	}
+	gr.scheduleBot()
+}
+
+// 観戦者へ送信する（ログには残さない。mu を保持した状態で呼ぶ）
+func (gr *gameRoom) sendSpectators(payload interface{}) {
+	for conn := range gr.spectators {
+		if err := conn.WriteJSON(payload); err != nil {
+			conn.Close()
+			delete(gr.spectators, conn)
+		}
+	}
+}
+
+// 対局者と観戦者へチャットを送信する（再接続用のログには残さない。mu を保持した状態で呼ぶ）
+func (gr *gameRoom) sendChat(payload map[string]interface{}) {
+	for conn := range gr.clients {
+		if err := conn.WriteJSON(payload); err != nil {
+			conn.Close()
+			delete(gr.clients, conn)
+		}
+	}
+	gr.sendSpectators(payload)
+}
+
+// 全クライアントへイベントを送信する（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) broadcast(payload map[string]interface{}) {
+	gr.emit("", payload)
+}
+
+// 各プレイヤーに自分視点の board_update を送信する（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) broadcastBoard() {
+	game := gr.match.Game()
+	for pid, color := range gr.match.Players() {
+		var boardToSend [][]int
+		if game.GetTurn() == color {
+			boardToSend = game.GetBoardWithValidMoves(color)
+		} else {
+			boardToSend = game.GetBoard()
+		}
+
+		payload := map[string]interface{}{
+			"type":        "board_update",
+			"board":       boardToSend,
+			"currentTurn": (game.GetTurnCount() + 1) / 2,
+			"isYourTurn":  (game.GetTurn() == color),
+		}
+		if gr.clock != nil {
+			payload["clock"] = gr.clockState()
+		}
+		if n := len(gr.actions); n > 0 {
+			payload["lastAction"] = gr.actions[n-1]
+		}
+		gr.emit(pid, payload)
+	}
+
+	if len(gr.spectators) > 0 {
+		payload := map[string]interface{}{
+			"type":        "board_update",
+			"board":       game.GetBoard(),
+			"currentTurn": (game.GetTurnCount() + 1) / 2,
+			"turn":        game.GetTurn(),
+		}
+		if gr.clock != nil {
+			payload["clock"] = gr.clockState()
+		}
+		gr.sendSpectators(payload)
+	}
+	gr.reportScore()
+	gr.scheduleBot()
+}
+
+// 終了した対局のハブを破棄し、ルームを閉じる（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) close() {
+	removeGameRoom(gr.id)
+	if err := Rooms.CloseRoom(gr.id); err != nil && !errors.Is(err, lobby.ErrRoomNotFound) {
+		log.Println("Failed to close room:", err)
+	}
+}
+
+// プレイヤーがルームから退出する（mu を保持した状態で呼ぶ）
+// 対局中なら負けとし、対局開始前なら席を空ける。終局後は全員が切断した時点でルームを閉じる
+// @param playerID 退出するプレイヤー
+// @return string 退出できなければエラーメッセージ
+func (gr *gameRoom) exit(playerID string) string {
+	if gr.over() {
+		return ""
+	}
+	if !gr.playing() {
+		closed, err := Rooms.LeaveRoom(playerID, gr.id)
+		if err != nil {
+			return err.Error()
+		}
+		gr.removePlayer(playerID, "left")
+		if closed {
+			removeGameRoom(gr.id)
+		}
+		return ""
+	}
+	result, err := gr.match.Forfeit(playerID)
+	if err != nil {
+		return err.Error()
+	}
+	gr.finish(result.Winner, result.Reason)
+	return ""
+}
+
+// 対局開始前のプレイヤーを席から外し、ルームに通知する（mu を保持した状態で呼ぶ）
+// @param playerID 外すプレイヤー
+// @param reason "kicked"（ホストによる退出）または "left"（自分で退出）
+func (gr *gameRoom) removePlayer(playerID, reason string) {
+	gr.broadcast(map[string]interface{}{
+		"type":     "player_removed",
+		"playerID": playerID,
+		"reason":   reason,
+	})
+	if timer, ok := gr.graceTimers[playerID]; ok {
+		timer.Stop()
+		delete(gr.graceTimers, playerID)
+	}
+	gr.match.Unseat(playerID)
+	delete(gr.bots, playerID)
+	gr.ready = make(map[string]bool)
+	gr.state = lobby.StatusWaiting
+	// 残ったプレイヤーは先手（Player1）に繰り上がる
+	gr.promoteToBlack()
+	if reason == "kicked" {
+		for conn, pid := range gr.clients {
+			if pid == playerID {
+				conn.Close()
+			}
+		}
+	}
+}
+
+// 残り時間を送信用に整形する（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) clockState() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"blackMs": gr.clock.Remaining(reversi.Black, now).Milliseconds(),
+		"whiteMs": gr.clock.Remaining(reversi.White, now).Milliseconds(),
+		"running": gr.clock.Turn(),
+	}
+}
+
+// 両プレイヤーが揃っていれば時計を動かし始める（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) startClock() {
+	if !gr.playing() {
+		return
+	}
+	if gr.clock == nil {
+		if len(gr.actions) == 0 {
+			gr.turnStartedAt = time.Now()
+		}
+		return
+	}
+	if gr.clock.Running() {
+		return
+	}
+	now := time.Now()
+	gr.turnStartedAt = now
+	gr.clock.Start(gr.match.Game().GetTurn(), now)
+	gr.scheduleFlag()
+}
+
+// 手番側の時間切れを検知するタイマーを張り直す（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) scheduleFlag() {
+	if gr.flagTimer != nil {
+		gr.flagTimer.Stop()
+	}
+	if !gr.clock.Running() {
+		return
+	}
+	remaining := gr.clock.Remaining(gr.clock.Turn(), time.Now())
+	gr.flagTimer = time.AfterFunc(remaining, func() {
+		gr.mu.Lock()
+		defer gr.mu.Unlock()
+		if !gr.checkFlag() && gr.clock.Running() {
+			gr.scheduleFlag()
+		}
+	})
+}
+
+// 手番側の持ち時間が切れていれば時間切れ負けとして対局を終了する（mu を保持した状態で呼ぶ）
+// @return bool 時間切れであれば true
+func (gr *gameRoom) checkFlag() bool {
+	if !gr.playing() || gr.clock == nil || !gr.clock.Running() {
+		return false
+	}
+	color := gr.clock.Turn()
+	if gr.clock.Remaining(color, time.Now()) > 0 {
+		return false
+	}
+	gr.finish(1-color, "timeout")
+	return true
+}
+
+// 手番の終了を記録し、相手の時計に切り替える（mu を保持した状態で呼ぶ）
+// @param playerID 手番を終えたプレイヤー
+// @param action 行ったアクション（move / operation / pass）
+// @param before アクション直前の局面
+func (gr *gameRoom) endTurn(playerID, action string, before gamesvc.Snapshot) {
+	gr.cancelRequest(requestDraw, requestTakeback)
+
+	now := time.Now()
+	used := now.Sub(gr.turnStartedAt)
+	gr.turnStartedAt = now
+
+	var clockBefore clock.State
+	if gr.clock != nil {
+		clockBefore = gr.clock.Snapshot()
+	}
+	if gr.clock != nil && gr.clock.Running() {
+		// 時間切れになったのは計測中だった側（手番を終えたプレイヤーとは限らない）
+		running := gr.clock.Turn()
+		clockUsed, flagged := gr.clock.Press(gr.match.Game().GetTurn(), now)
+		used = clockUsed
+		if flagged {
+			gr.finish(1-running, "timeout")
+			return
+		}
+		gr.scheduleFlag()
+	}
+
+	gr.actions = append(gr.actions, actionRecord{
+		PlayerID: playerID,
+		Action:   action,
+		Used:     used,
+		UsedMs:   used.Milliseconds(),
+	})
+	gr.history = append(gr.history, before)
+	gr.clocks = append(gr.clocks, clockBefore)
+}
+
+// 対局を終了し game_over を送信する（mu を保持した状態で呼ぶ）
+// @param winner 勝者（Black=1, White=0, 引き分け=-1）
+// @param reason 終了理由
+func (gr *gameRoom) finish(winner int, reason string) {
+	if !gr.playing() {
+		return
+	}
+	gr.end(lobby.StatusFinished, winner, reason)
+
+	if record := gr.buildRecord(winner, reason); record != nil {
+		go recordGameResult(record)
+	}
+}
+
+// 指定シーケンス番号より後のイベントをプレイヤーに再送する（mu を保持した状態で呼ぶ）
+// @return bool ログが欠落しており再送できなければ false
+func (gr *gameRoom) replay(conn *websocket.Conn, playerID string, lastSeq int) bool {
+	if lastSeq < gr.seq && len(gr.events) > 0 && gr.events[0].Seq > lastSeq+1 {
+		return false
+	}
+	for _, ev := range gr.events {
+		if ev.Seq <= lastSeq {
+			continue
+		}
+		if ev.PlayerID != "" && ev.PlayerID != playerID {
+			continue
+		}
+		if err := conn.WriteJSON(ev.Payload); err != nil {
+			return true
+		}
+	}
+	return true
+}
+
+// 接続を登録し、猶予タイマーが動いていれば再接続として扱う
+func (gr *gameRoom) attach(conn *websocket.Conn, playerID string) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	reconnected := false
+	if timer, ok := gr.graceTimers[playerID]; ok {
+		timer.Stop()
+		delete(gr.graceTimers, playerID)
+		reconnected = true
+	}
+	gr.clients[conn] = playerID
+
+	if opponent := gr.match.Opponent(playerID); reconnected && opponent != "" {
+		gr.emit(opponent, map[string]interface{}{
+			"type":     "opponent_reconnected",
+			"playerID": playerID,
+		})
+	}
+	gr.begin()
+	gr.startClock()
+	gr.scheduleBot()
+}
+
+// 観戦者の接続を登録する
+func (gr *gameRoom) attachSpectator(conn *websocket.Conn, playerID string) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	gr.spectators[conn] = playerID
+}
+
+// 観戦者の接続を外す
+func (gr *gameRoom) detachSpectator(conn *websocket.Conn) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	delete(gr.spectators, conn)
+	if gr.over() && len(gr.clients) == 0 && len(gr.spectators) == 0 {
+		gr.close()
+	}
+}
+
+// 接続を外し、プレイヤーが完全に切断された場合は猶予タイマーを開始する
+func (gr *gameRoom) detach(conn *websocket.Conn) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	playerID, ok := gr.clients[conn]
+	if !ok {
+		return
+	}
+	delete(gr.clients, conn)
+
+	if gr.isConnected(playerID) {
+		return
+	}
+	if gr.over() {
+		if len(gr.clients) == 0 && len(gr.spectators) == 0 {
+			gr.close()
+		}
+		return
+	}
+	if _, seated := gr.match.Color(playerID); !seated {
+		return
+	}
+	if !gr.playing() {
+		// 開始前に切断したプレイヤーは準備完了を取り消す
+		if gr.ready[playerID] && !gr.bots[playerID] {
+			delete(gr.ready, playerID)
+			gr.broadcast(map[string]interface{}{
+				"type":     "player_unready",
+				"playerID": playerID,
+			})
+		}
+		return
+	}
+
+	grace := ReconnectGracePeriod
+	gr.emit(gr.match.Opponent(playerID), map[string]interface{}{
+		"type":         "opponent_disconnected",
+		"playerID":     playerID,
+		"graceSeconds": int(grace / time.Second),
+	})
+	gr.graceTimers[playerID] = time.AfterFunc(grace, func() {
+		gr.mu.Lock()
+		defer gr.mu.Unlock()
+		if _, waiting := gr.graceTimers[playerID]; !waiting {
+			return
+		}
+		delete(gr.graceTimers, playerID)
+		gr.expireGrace(playerID)
+	})
+}
@@ -0,0 +1,270 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	gamesvc "be-binareversi/service/game"
+	"be-binareversi/service/lobby"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+)
+
+var errGameNotStarted = errors.New("game has not started")
+
+// 対局中かどうか（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) playing() bool {
+	return gr.state == lobby.StatusInProgress
+}
+
+// 終局後かどうか（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) over() bool {
+	return lobby.IsOver(gr.state)
+}
+
+// 着手できる状態かを確かめる（mu を保持した状態で呼ぶ）
+// @return error 終局後なら errGameOver、開始前なら errGameNotStarted
+func (gr *gameRoom) checkPlayable() error {
+	if gr.checkFlag() || gr.over() {
+		return errGameOver
+	}
+	if !gr.playing() {
+		return errGameNotStarted
+	}
+	return nil
+}
+
+// 状態を遷移させて永続化し、ルームに room_state を送信する（mu を保持した状態で呼ぶ）
+// @param to 遷移先
+// @return error 遷移できない、または永続化に失敗した場合はエラー（状態は変えない）
+func (gr *gameRoom) transition(to string) error {
+	if gr.state == to {
+		return nil
+	}
+	if !lobby.CanTransition(gr.state, to) {
+		return lobby.ErrInvalidTransition
+	}
+	score := gr.score()
+	if err := Rooms.ReportStatus(gr.id, to, score); err != nil && !errors.Is(err, lobby.ErrRoomNotFound) {
+		return err
+	}
+	gr.state, gr.reported = to, score
+	gr.broadcast(map[string]interface{}{
+		"type":  "room_state",
+		"state": to,
+	})
+	return nil
+}
+
+// 現在の石数
+func (gr *gameRoom) score() lobby.Score {
+	black, white := gr.match.Game().CountDiscs()
+	return lobby.Score{Black: black, White: white}
+}
+
+// 対局中に石数が変わっていればロビーに通知する（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) reportScore() {
+	score := gr.score()
+	if !gr.playing() || score == gr.reported {
+		return
+	}
+	gr.reported = score
+	if err := Rooms.ReportStatus(gr.id, gr.state, score); err != nil && !errors.Is(err, lobby.ErrRoomNotFound) {
+		log.Println("Failed to report room status:", err)
+	}
+}
+
+// 対局開始前の状態をルームに合わせる（開始前の席の出入りはロビー側で管理する。mu を保持した状態で呼ぶ）
+func (gr *gameRoom) syncState(room *model.Room) {
+	if gr.playing() || gr.over() {
+		return
+	}
+	gr.state = room.Status
+	for pid := range gr.ready {
+		if _, seated := gr.match.Color(pid); !seated {
+			delete(gr.ready, pid)
+		}
+	}
+}
+
+// 準備完了を受け付け、揃っていれば対局を始める（mu を保持した状態で呼ぶ）
+// @param playerID 準備完了したプレイヤー
+// @return string 受け付けられなければエラーメッセージ
+func (gr *gameRoom) markReady(playerID string) string {
+	if gr.state != lobby.StatusReady {
+		return "room is not ready to start"
+	}
+	if _, seated := gr.match.Color(playerID); !seated {
+		return "spectators cannot play"
+	}
+	if !gr.ready[playerID] {
+		gr.ready[playerID] = true
+		gr.broadcast(map[string]interface{}{
+			"type":     "player_ready",
+			"playerID": playerID,
+		})
+	}
+	gr.begin()
+	return ""
+}
+
+// 2人が着席・接続・準備完了していれば対局を始める（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) begin() {
+	players := gr.match.Players()
+	if gr.state != lobby.StatusReady || len(players) < 2 {
+		return
+	}
+	for pid := range players {
+		if !gr.ready[pid] || !gr.isConnected(pid) {
+			return
+		}
+	}
+	gr.prepare()
+	if err := gr.transition(lobby.StatusInProgress); err != nil {
+		log.Println("Failed to start game:", err)
+		return
+	}
+	gr.ready = make(map[string]bool)
+	gr.startedAt = time.Now()
+	// 確定した色を game_start で知らせ直す
+	for conn, pid := range gr.clients {
+		color, _ := gr.match.Color(pid)
+		sendGameStart(gr, conn, pid, color)
+	}
+	gr.startClock()
+	gr.broadcastBoard()
+}
+
+// ルームの設定から色と開始局面を決める（対局開始・再戦の直前に、mu を保持した状態で呼ぶ）
+func (gr *gameRoom) prepare() {
+	room, err := Rooms.Room(gr.id)
+	if err != nil {
+		return
+	}
+	gr.assignColors(room)
+	gr.applyOpening(room)
+}
+
+// ルームの設定に従って先手を決め、席を並べ替える（mu を保持した状態で呼ぶ）
+// alternate は着席時（作成者が先手）と再戦時の入れ替えのままにする
+func (gr *gameRoom) assignColors(room *model.Room) {
+	creator := room.Player1
+	opponent := gr.match.Opponent(creator)
+	if opponent == "" {
+		return
+	}
+
+	var creatorColor int
+	switch room.ColorMode {
+	case lobby.ColorModeBlack:
+		creatorColor = reversi.Black
+	case lobby.ColorModeWhite:
+		creatorColor = reversi.White
+	case lobby.ColorModeRandom:
+		creatorColor = rand.Intn(2)
+	default:
+		return
+	}
+	gr.match.Unseat(creator)
+	gr.match.Unseat(opponent)
+	gr.match.Seat(creator, creatorColor)
+	gr.match.Seat(opponent, 1-creatorColor)
+}
+
+// ルームの設定に従って開始局面を並べる（色が決まった後に、mu を保持した状態で呼ぶ）
+func (gr *gameRoom) applyOpening(room *model.Room) {
+	setup, err := lobby.SetupOf(room, gr.weakerColor(), rand.Intn)
+	if err != nil {
+		// 作成時に確かめているので、ここで失敗するのは保存後に壊れた場合だけ
+		log.Println("Invalid opening, using the standard one:", err)
+		setup = reversi.StandardSetup(lobby.BoardSizeOf(room))
+	}
+	gr.match.Reset(setup)
+	// 8x8 の標準以外は、手順を再生できるよう局面文字列（大きさも表す）を記録する
+	gr.startPosition = ""
+	if !setup.Equal(reversi.StandardSetup(reversi.DefaultSize)) {
+		gr.startPosition = setup.String()
+	}
+}
+
+// レーティングの低い側の色（同じなら後手）
+func (gr *gameRoom) weakerColor() int {
+	rating := func(color int) float64 {
+		if player, err := db.GetPlayerByID(gr.match.PlayerOf(color)); err == nil {
+			return player.Rating
+		}
+		return 0
+	}
+	if rating(reversi.Black) < rating(reversi.White) {
+		return reversi.Black
+	}
+	return reversi.White
+}
+
+// 対局を終える（mu を保持した状態で呼ぶ）
+// @param state lobby.StatusFinished または lobby.StatusAbandoned
+// @param winner 勝者（Black=1, White=0, 引き分け=-1）
+// @param reason 終了理由
+func (gr *gameRoom) end(state string, winner int, reason string) {
+	gr.cancelRequest(requestDraw, requestTakeback)
+	if gr.clock != nil {
+		gr.clock.Stop(time.Now())
+	}
+	if gr.flagTimer != nil {
+		gr.flagTimer.Stop()
+	}
+	for pid, timer := range gr.graceTimers {
+		timer.Stop()
+		delete(gr.graceTimers, pid)
+	}
+	gr.broadcast(map[string]interface{}{
+		"type":   "game_over",
+		"winner": winner,
+		"reason": reason,
+	})
+	if err := gr.transition(state); err != nil {
+		// 永続化できなくても対局は終える
+		log.Println("Failed to record game end:", err)
+		gr.state = state
+	}
+}
+
+// 両者とも戻らなかった対局を勝敗なしで終え、誰もいなければルームを閉じる（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) abandon() {
+	if !gr.playing() {
+		return
+	}
+	gr.end(lobby.StatusAbandoned, gamesvc.Draw, lobby.StatusAbandoned)
+	if len(gr.clients) == 0 && len(gr.spectators) == 0 {
+		gr.close()
+	}
+}
+
+// 猶予時間が過ぎても戻らなかったプレイヤーを処理する（mu を保持した状態で呼ぶ）
+// 相手が接続していれば不戦敗、相手もいなければ放棄とする
+func (gr *gameRoom) expireGrace(playerID string) {
+	opponent := gr.match.Opponent(playerID)
+	if opponent != "" && gr.isConnected(opponent) {
+		color, _ := gr.match.Color(playerID)
+		gr.finish(1-color, "disconnect")
+		return
+	}
+	gr.abandon()
+}
+
+// 先手の席が空いていれば残ったプレイヤーを繰り上げる（mu を保持した状態で呼ぶ）
+func (gr *gameRoom) promoteToBlack() {
+	for pid, color := range gr.match.Players() {
+		if color == reversi.Black {
+			continue
+		}
+		gr.match.Unseat(pid)
+		gr.match.Seat(pid, reversi.Black)
+		gr.emit(pid, map[string]interface{}{
+			"type":      "seat_changed",
+			"yourColor": reversi.Black,
+		})
+	}
+}
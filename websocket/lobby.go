@@ -2,40 +2,91 @@ package websocket
 
 import (
 	"be-binareversi/db"
-	"be-binareversi/model"
+	"be-binareversi/service/lobby"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
+)
+
+// ルーム操作の業務ロジック（main で設定する）
+var Rooms lobby.Service
+
+// ロビーの購読者へ配信する lobby.Notifier
+type lobbyNotifier struct{}
+
+func (lobbyNotifier) Broadcast(event map[string]interface{}) {
+	lobbyClients.Broadcast(event)
+}
+
+// ロビーの websocket 購読者へ配信する Notifier を返す
+func LobbyNotifier() lobby.Notifier {
+	return lobbyNotifier{}
+}
+
+// lobby メッセージからルーム作成の指定を読み取る
+func roomOptionsFromMessage(msg map[string]string) lobby.RoomOptions {
+	atoi := func(key string) int {
+		v, _ := strconv.Atoi(msg[key])
+		return v
+	}
+	return lobby.RoomOptions{
+		Variant:          msg["variant"],
+		TimeControl:      msg["timeControl"],
+		InitialSeconds:   atoi("initialSeconds"),
+		IncrementSeconds: atoi("incrementSeconds"),
+		MoveSeconds:      atoi("moveSeconds"),
+		ColorMode:        msg["colorMode"],
+		BoardSize:        atoi("boardSize"),
+		Opening:          msg["opening"],
+		Handicap:         atoi("handicap"),
+		Position:         msg["position"],
+		AllowSpectators:  msg["allowSpectators"] == "true",
+		Private:          msg["private"] == "true",
+		Password:         msg["password"],
+		ReservedFor:      msg["reservedFor"],
+	}
+}
 
-	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
+// room_init で1度に返すルーム数の既定値と上限
+const (
+	roomPageSize    = 50
+	roomPageSizeMax = 100
 )
 
-// WebSocketクライアント管理
-var lobbyClients = map[*websocket.Conn]bool{}
-var lobbyBroadcast = make(chan interface{})
-var roomMu sync.RWMutex
-
-// レスポンス用構造体（ID）
-type RoomResponse struct {
-	ID        string    `json:"id"`
-	Player1   string    `json:"player1"`           // id
-	Player2   string    `json:"player2,omitempty"` // id
-	IsFull    bool      `json:"isFull"`
-	CreatedAt time.Time `json:"createdAt"`
+// room_init メッセージから一覧の絞り込み・並び順・ページの指定を読み取る
+func roomFilterFromMessage(msg map[string]string) lobby.RoomFilter {
+	limit, err := strconv.Atoi(msg["limit"])
+	if err != nil || limit <= 0 || limit > roomPageSizeMax {
+		limit = roomPageSize
+	}
+	minRating, _ := strconv.ParseFloat(msg["minRating"], 64)
+	maxRating, _ := strconv.ParseFloat(msg["maxRating"], 64)
+	return lobby.RoomFilter{
+		Status:    msg["status"],
+		Variant:   msg["variant"],
+		MinRating: minRating,
+		MaxRating: maxRating,
+		Sort:      msg["sort"],
+		Cursor:    msg["cursor"],
+		Limit:     limit,
+	}
 }
 
-func HandleLobby(w http.ResponseWriter, r *http.Request) {
+// playerID はセッショントークンで認証済みのプレイヤー
+func HandleLobby(playerID string, w http.ResponseWriter, r *http.Request) {
 	conn, err := Upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
+	client := newLobbyClient(conn, playerID, playerName(playerID))
+	lobbyClients.register <- client
+	go client.writePump()
 	defer func() {
-		conn.Close()
-		delete(lobbyClients, conn)
+		lobbyClients.unregister <- client
+		matchQueue.removeClient(client)
+		challenges.removeClient(client)
 	}()
 
-	lobbyClients[conn] = true
 	for {
 		var msg map[string]string
 		if err := conn.ReadJSON(&msg); err != nil {
@@ -44,108 +95,117 @@ func HandleLobby(w http.ResponseWriter, r *http.Request) {
 
 		switch msg["type"] {
 		case "room_init":
-			roomMu.RLock()
-			roomList := []*RoomResponse{}
-			rooms, _ := db.GetAllRooms()
-			for _, room := range rooms {
-				player1, _ := db.GetPlayerByID(room.Player1)
-				var player2Name string
-				if room.Player2 != nil {
-					player2, _ := db.GetPlayerByID(*room.Player2)
-					if player2 != nil {
-						player2Name = player2.Name
-					}
-				}
-				roomList = append(roomList, &RoomResponse{
-					ID:        room.ID,
-					Player1:   player1.Name,
-					Player2:   player2Name,
-					IsFull:    room.IsFull,
-					CreatedAt: room.CreatedAt,
-				})
+			page, err := Rooms.ListRooms(roomFilterFromMessage(msg))
+			if err != nil {
+				client.push(map[string]string{"error": err.Error()})
+				continue
 			}
-			roomMu.RUnlock()
-			// roomListの長さを出力
-			println("roomList length:", len(roomList))
-			conn.WriteJSON(map[string]interface{}{
-				"type":  "room_list",
-				"rooms": roomList,
+			client.push(map[string]interface{}{
+				"type":       "room_list",
+				"rooms":      page.Rooms,
+				"nextCursor": page.NextCursor,
 			})
+
+		case "who_is_online":
+			lobbyClients.whoIsOnline(client)
+
 		case "create_room":
-			playerID := msg["playerID"]
+			resp, err := Rooms.CreateRoom(playerID, roomOptionsFromMessage(msg))
+			if err != nil {
+				client.push(map[string]string{"error": err.Error()})
+				continue
+			}
+			if resp.IsPrivate {
+				client.push(map[string]interface{}{"type": "room_created", "room": resp})
+			}
 
+		case "find_match":
 			player, err := db.GetPlayerByID(playerID)
 			if err != nil || player == nil {
-				conn.WriteJSON(map[string]string{"error": "invalid playerID"})
+				client.push(map[string]string{"error": "invalid playerID"})
+				continue
+			}
+			variant := msg["variant"]
+			if variant == "" {
+				variant = matchAnyVariant
+			}
+			if _, ok := matchVariants[variant]; !ok && variant != matchAnyVariant {
+				client.push(map[string]string{"error": "unknown variant"})
 				continue
 			}
 
-			roomMu.Lock()
-			roomID := uuid.New().String()
-			room := &model.Room{ID: roomID, Player1: playerID, IsFull: false}
-			model.Rooms[roomID] = room
-			db.CreateRoom(room)
-			roomMu.Unlock()
-
-			resp := RoomResponse{
-				ID:        roomID,
-				Player1:   player.Name,
-				Player2:   "",
-				IsFull:    false,
-				CreatedAt: room.CreatedAt,
+			ticket := &matchTicket{
+				playerID: playerID,
+				name:     player.Name,
+				variant:  variant,
+				rating:   playerRating(playerID),
+				client:   client,
+				joinedAt: time.Now(),
+			}
+			if err := matchQueue.enqueue(ticket); err != nil {
+				client.push(map[string]string{"error": err.Error()})
+				continue
 			}
-			lobbyBroadcast <- map[string]interface{}{"type": "room_created", "room": resp}
+			lobbyClients.setQueued(playerID, true)
+			client.push(map[string]interface{}{
+				"type":           "match_searching",
+				"variant":        variant,
+				"timeoutSeconds": int(MatchTimeout / time.Second),
+			})
 
-		case "join_room":
-			roomID := msg["roomID"]
-			playerID := msg["playerID"]
+		case "cancel_match":
+			if !matchQueue.cancel(playerID) {
+				client.push(map[string]string{"error": "not searching for a match"})
+				continue
+			}
+			lobbyClients.setQueued(playerID, false)
+			client.push(map[string]string{"type": "match_cancelled"})
 
-			player, err := db.GetPlayerByID(playerID)
-			if err != nil || player == nil {
-				conn.WriteJSON(map[string]string{"error": "invalid playerID"})
+		case "challenge":
+			c, err := challenges.open(client, msg["playerID"], msg["variant"], msg["color"])
+			if err != nil {
+				client.push(map[string]string{"error": err.Error()})
 				continue
 			}
+			client.push(c.payload("challenge_sent"))
 
-			roomMu.Lock()
-			room, ok := model.Rooms[roomID]
-			if ok && !room.IsFull {
-				room.Player2 = &playerID
-				room.IsFull = true
-				db.UpdateRoom(room)
-				roomMu.Unlock()
-
-				player1, _ := db.GetPlayerByID(room.Player1)
-				player2 := player.Name
-				player1Name := "unknown"
-				if player1 != nil {
-					player1Name = player1.Name
-				}
-
-				resp := RoomResponse{
-					ID:        room.ID,
-					Player1:   player1Name,
-					Player2:   player2,
-					IsFull:    true,
-					CreatedAt: room.CreatedAt,
-				}
-				lobbyBroadcast <- map[string]interface{}{"type": "room_updated", "room": resp}
-			} else {
-				roomMu.Unlock()
-				conn.WriteJSON(map[string]string{"error": "room not found or already full"})
+		case "challenge_answer":
+			if err := challenges.answer(client, msg["challengeID"], msg["accept"] == "true"); err != nil {
+				client.push(map[string]string{"error": err.Error()})
 			}
-		}
-	}
-}
 
-func init() {
-	go func() {
-		for msg := range lobbyBroadcast {
-			for conn := range lobbyClients {
-				if err := conn.WriteJSON(msg); err != nil {
-					conn.Close()
-					delete(lobbyClients, conn)
-				}
+		case "cancel_challenge":
+			if err := challenges.cancel(playerID, msg["challengeID"]); err != nil {
+				client.push(map[string]string{"error": err.Error()})
+			}
+
+		case "chat":
+			chatMsg, err := postChat(lobbyChat, "", chatChannelLobby, playerID, msg["text"])
+			if err != nil {
+				client.push(map[string]string{"error": err.Error()})
+				continue
+			}
+			lobbyClients.Broadcast(map[string]interface{}{"type": "chat", "message": chatMsg})
+
+		case "chat_history":
+			client.push(map[string]interface{}{
+				"type":     "chat_history",
+				"channel":  chatChannelLobby,
+				"messages": lobbyChat.List(),
+			})
+
+		case "join_room":
+			resp, err := Rooms.JoinRoom(playerID, msg["roomID"], lobby.JoinOptions{
+				InviteCode: msg["inviteCode"],
+				Password:   msg["password"],
+			})
+			if err != nil {
+				client.push(map[string]string{"error": err.Error()})
+				continue
+			}
+			if resp.IsPrivate {
+				client.push(map[string]interface{}{"type": "room_updated", "room": resp})
 			}
 		}
-	}()
+	}
 }
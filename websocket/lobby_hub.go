@@ -0,0 +1,229 @@
+package websocket
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	lobbySendBuffer      = 64               // クライアントごとの送信待ちの上限
+	lobbyBroadcastBuffer = 256              // ハブが受け付ける配信待ちの上限
+	lobbyWriteWait       = 10 * time.Second // 1件の書き込みにかける時間の上限
+)
+
+// lobbyClient はロビーに接続中のクライアント
+type lobbyClient struct {
+	conn     *websocket.Conn
+	playerID string
+	name     string
+	send     chan interface{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// 新しいクライアントを作成する
+// @param conn ロビーの websocket 接続
+// @param playerID 認証済みのプレイヤー
+// @param name 表示名
+// @return *lobbyClient クライアント
+func newLobbyClient(conn *websocket.Conn, playerID, name string) *lobbyClient {
+	return &lobbyClient{
+		conn:     conn,
+		playerID: playerID,
+		name:     name,
+		send:     make(chan interface{}, lobbySendBuffer),
+		done:     make(chan struct{}),
+	}
+}
+
+// 送信待ちに積む（ブロックしない）
+// 送信待ちが溢れたクライアントは追いつけないものとして切断する
+// @param msg 送信内容
+// @return bool 積めなければ false
+func (c *lobbyClient) push(msg interface{}) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		log.Println("Lobby client is too slow, disconnecting:", c.playerID)
+		c.close()
+		return false
+	}
+}
+
+// 接続を閉じる（何度呼んでもよい）
+func (c *lobbyClient) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// 送信待ちを順に書き込む（接続ごとに1つだけ動かす）
+func (c *lobbyClient) writePump() {
+	defer c.close()
+	for {
+		select {
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(lobbyWriteWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// lobbyHub はロビーのクライアント一覧とプレイヤーの在席状況を1つのゴルーチンで管理する
+type lobbyHub struct {
+	register   chan *lobbyClient
+	unregister chan *lobbyClient
+	broadcast  chan interface{}
+	updates    chan presenceUpdate
+	who        chan *lobbyClient
+	direct     chan directMessage
+	lookup     chan presenceQuery
+	clients    map[*lobbyClient]bool
+	presence   map[string]*presenceEntry
+}
+
+var lobbyClients = newLobbyHub()
+
+// 新しいハブを作成する
+func newLobbyHub() *lobbyHub {
+	return &lobbyHub{
+		register:   make(chan *lobbyClient),
+		unregister: make(chan *lobbyClient),
+		broadcast:  make(chan interface{}, lobbyBroadcastBuffer),
+		updates:    make(chan presenceUpdate),
+		who:        make(chan *lobbyClient),
+		direct:     make(chan directMessage),
+		lookup:     make(chan presenceQuery),
+		clients:    make(map[*lobbyClient]bool),
+		presence:   make(map[string]*presenceEntry),
+	}
+}
+
+// 全クライアントへ配信する（ブロックしない）
+// リクエストの処理中（ゲームルームのロックを保持している場合を含む）から呼ばれるため、待たずに配信待ちに積む
+// 遅いクライアントはハブが切断するので、配信待ちが溢れるのはハブ自体が止まっているときだけ
+// @param msg 配信内容
+func (h *lobbyHub) Broadcast(msg interface{}) {
+	select {
+	case h.broadcast <- msg:
+	default:
+		log.Println("Lobby broadcast queue is full, dropping message")
+	}
+}
+
+// オンラインのプレイヤー一覧をクライアントの送信待ちに積む
+// @param c 問い合わせたクライアント
+func (h *lobbyHub) whoIsOnline(c *lobbyClient) {
+	h.who <- c
+}
+
+// directMessage は特定のプレイヤーだけに送る内容
+type directMessage struct {
+	playerID string
+	msg      interface{}
+}
+
+// 特定のプレイヤーのロビー接続すべての送信待ちに積む
+// @param playerID 送信先のプレイヤー
+// @param msg 送信内容
+func (h *lobbyHub) sendTo(playerID string, msg interface{}) {
+	h.direct <- directMessage{playerID: playerID, msg: msg}
+}
+
+// presenceQuery はプレイヤーの状態の問い合わせ
+type presenceQuery struct {
+	playerID string
+	reply    chan string
+}
+
+// プレイヤーの現在の状態を返す
+// @param playerID プレイヤー
+// @return string 状態（接続していなければ presenceOffline）
+func (h *lobbyHub) statusOf(playerID string) string {
+	reply := make(chan string, 1)
+	h.lookup <- presenceQuery{playerID: playerID, reply: reply}
+	return <-reply
+}
+
+// 登録・解除・配信を処理し続ける
+func (h *lobbyHub) run() {
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c] = true
+			h.updatePresence(presenceUpdate{playerID: c.playerID, name: c.name, apply: func(p *presenceEntry) {
+				p.lobby++
+			}})
+		case c := <-h.unregister:
+			h.remove(c)
+		case msg := <-h.broadcast:
+			h.deliver(msg)
+		case u := <-h.updates:
+			h.updatePresence(u)
+		case d := <-h.direct:
+			var lagging []*lobbyClient
+			for c := range h.clients {
+				if c.playerID == d.playerID && !c.push(d.msg) {
+					lagging = append(lagging, c)
+				}
+			}
+			for _, c := range lagging {
+				h.remove(c)
+			}
+		case q := <-h.lookup:
+			status := presenceOffline
+			if p, ok := h.presence[q.playerID]; ok {
+				status = p.status()
+			}
+			q.reply <- status
+		case c := <-h.who:
+			c.push(map[string]interface{}{
+				"type":    "online_players",
+				"players": h.online(),
+			})
+		}
+	}
+}
+
+// 全クライアントの送信待ちに積み、溢れたクライアントを外す（ハブのゴルーチンで呼ぶ）
+func (h *lobbyHub) deliver(msg interface{}) {
+	var lagging []*lobbyClient
+	for c := range h.clients {
+		if !c.push(msg) {
+			lagging = append(lagging, c)
+		}
+	}
+	for _, c := range lagging {
+		h.remove(c)
+	}
+}
+
+// クライアントを外して接続を閉じる（ハブのゴルーチンで呼ぶ）
+func (h *lobbyHub) remove(c *lobbyClient) {
+	c.close()
+	if !h.clients[c] {
+		return
+	}
+	delete(h.clients, c)
+	h.updatePresence(presenceUpdate{playerID: c.playerID, apply: func(p *presenceEntry) {
+		p.lobby--
+	}})
+}
+
+func init() {
+	go lobbyClients.run()
+}
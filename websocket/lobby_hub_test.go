@@ -0,0 +1,156 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// テスト用のサーバーにつないだ websocket 接続を返す
+func dialTestConn(t *testing.T) *websocket.Conn {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		// クライアントが閉じるまで読み捨てる
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	return conn
+}
+
+func Test07_PresenceStatusPriority(t *testing.T) {
+	for _, c := range []struct {
+		entry presenceEntry
+		want  string
+	}{
+		{presenceEntry{}, presenceOffline},
+		{presenceEntry{lobby: 1}, presenceIdle},
+		{presenceEntry{lobby: 1, queued: true}, presenceInQueue},
+		{presenceEntry{queued: true, activity: map[string]int{presenceSpectating: 1}}, presenceSpectating},
+		{presenceEntry{lobby: 1, queued: true, activity: map[string]int{presenceSpectating: 2, presencePlaying: 1}}, presencePlaying},
+		// マッチング待ちでもロビーにもルームにもいなければオフライン
+		{presenceEntry{queued: true}, presenceOffline},
+	} {
+		if got := c.entry.status(); got != c.want {
+			t.Errorf("status(%+v) = %s, want %s", c.entry, got, c.want)
+		}
+	}
+}
+
+func Test08_UpdatePresenceBroadcastsChanges(t *testing.T) {
+	// ハブのゴルーチンは動かさずに直接呼ぶ
+	hub := newLobbyHub()
+	watcher := newLobbyClient(nil, "watcher", "watcher")
+	hub.clients[watcher] = true
+
+	presences := func() []map[string]interface{} {
+		var out []map[string]interface{}
+		for {
+			select {
+			case msg := <-watcher.send:
+				out = append(out, msg.(map[string]interface{}))
+			default:
+				return out
+			}
+		}
+	}
+	update := func(name string, apply func(p *presenceEntry)) {
+		hub.updatePresence(presenceUpdate{playerID: "alice", name: name, apply: apply})
+	}
+
+	update("alice", func(p *presenceEntry) { p.lobby++ })
+	if got := presences(); len(got) != 1 || got[0]["status"] != presenceIdle || got[0]["name"] != "alice" {
+		t.Errorf("Expected idle presence for alice, got %v", got)
+	}
+
+	// 状態が変わらなければ配信しない
+	update("", func(p *presenceEntry) { p.lobby++ })
+	if got := presences(); len(got) != 0 {
+		t.Errorf("Expected no presence for an unchanged status, got %v", got)
+	}
+
+	update("", func(p *presenceEntry) { p.activity[presencePlaying]++ })
+	update("", func(p *presenceEntry) { p.queued = true })
+	if got := presences(); len(got) != 1 || got[0]["status"] != presencePlaying {
+		t.Errorf("Expected only the playing presence, got %v", got)
+	}
+	if players := hub.online(); len(players) != 1 || players[0].Status != presencePlaying {
+		t.Errorf("Expected alice to be listed as playing, got %+v", players)
+	}
+
+	// すべての接続が外れたら一覧から消える
+	update("", func(p *presenceEntry) {
+		p.lobby = 0
+		delete(p.activity, presencePlaying)
+	})
+	if got := presences(); len(got) != 1 || got[0]["status"] != presenceOffline {
+		t.Errorf("Expected offline presence, got %v", got)
+	}
+	if _, ok := hub.presence["alice"]; ok {
+		t.Error("Expected offline player to be removed")
+	}
+}
+
+func Test09_BroadcastDisconnectsLaggingClients(t *testing.T) {
+	hub := newLobbyHub()
+	go hub.run()
+	fast := newLobbyClient(dialTestConn(t), "fast", "fast")
+	slow := newLobbyClient(dialTestConn(t), "slow", "slow")
+	hub.register <- fast
+	hub.register <- slow
+
+	// 送信待ちを溢れさせたクライアントだけが切断され、他のクライアントには届き続ける
+	for i := 0; i < lobbySendBuffer+lobbyBroadcastBuffer; i++ {
+		hub.Broadcast(map[string]interface{}{"type": "tick", "n": i})
+		select {
+		case <-fast.send:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected tick %d to reach the fast client", i)
+		}
+	}
+
+	select {
+	case <-slow.done:
+	default:
+		t.Fatal("Expected lagging client to be disconnected")
+	}
+	if status := hub.statusOf("slow"); status != presenceOffline {
+		t.Errorf("Expected lagging client to be removed, got %s", status)
+	}
+	if status := hub.statusOf("fast"); status != presenceIdle {
+		t.Errorf("Expected fast client to stay connected, got %s", status)
+	}
+}
+
+func Test11_BroadcastNeverBlocks(t *testing.T) {
+	// ハブが止まっていても、呼び出し元（リクエストの処理）は待たされない
+	hub := newLobbyHub()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < lobbyBroadcastBuffer+1; i++ {
+			hub.Broadcast(map[string]interface{}{"type": "tick", "n": i})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Broadcast to return while the hub is stalled")
+	}
+}
@@ -0,0 +1,277 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/glicko2"
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	"be-binareversi/service/lobby"
+	"errors"
+	"log"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// 自動マッチングで選べるルール（"any" はどれでもよい）
+const matchAnyVariant = "any"
+
+var matchVariants = lobby.Variants
+
+// 相手が見つからないときにAI戦へ切り替えるまでの時間
+var MatchTimeout = 60 * time.Second
+
+const (
+	matchBaseBand     = 100.0           // 最初に許容するレーティング差
+	matchBandStep     = 50.0            // 待ち時間に応じて広げる幅
+	matchBandInterval = 5 * time.Second // 幅を広げる間隔
+	matchTickInterval = time.Second
+)
+
+var errAlreadyQueued = errors.New("already searching for a match")
+
+// マッチング待ちのプレイヤー
+type matchTicket struct {
+	playerID string
+	name     string
+	variant  string
+	rating   float64
+	client   *lobbyClient // AIの場合は nil
+	joinedAt time.Time
+}
+
+// 待ち時間に応じた許容レーティング差を返す
+func (t *matchTicket) band(now time.Time) float64 {
+	steps := float64(now.Sub(t.joinedAt) / matchBandInterval)
+	return matchBaseBand + matchBandStep*steps
+}
+
+// matchmaker はマッチング待ちの列を管理する
+type matchmaker struct {
+	mu      sync.Mutex
+	tickets []*matchTicket
+}
+
+var matchQueue = &matchmaker{}
+
+// プレイヤーのレーティングを返す
+func playerRating(playerID string) float64 {
+	player, err := db.GetPlayerByID(playerID)
+	if err != nil || player.Rating == 0 {
+		return glicko2.DefaultRating
+	}
+	return player.Rating
+}
+
+// 列に追加する
+func (m *matchmaker) enqueue(t *matchTicket) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, queued := range m.tickets {
+		if queued.playerID == t.playerID {
+			return errAlreadyQueued
+		}
+	}
+	m.tickets = append(m.tickets, t)
+	return nil
+}
+
+// 列から外す
+// @return bool 列に並んでいれば true
+func (m *matchmaker) cancel(playerID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, t := range m.tickets {
+		if t.playerID == playerID {
+			m.tickets = append(m.tickets[:i], m.tickets[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// 切断したロビー接続の待ちをすべて外す
+func (m *matchmaker) removeClient(client *lobbyClient) {
+	m.mu.Lock()
+	kept := m.tickets[:0]
+	var removed []string
+	for _, t := range m.tickets {
+		if t.client != client {
+			kept = append(kept, t)
+		} else {
+			removed = append(removed, t.playerID)
+		}
+	}
+	m.tickets = kept
+	m.mu.Unlock()
+
+	for _, playerID := range removed {
+		lobbyClients.setQueued(playerID, false)
+	}
+}
+
+// 2人が対戦可能なら決定したルールを返す
+func matchVariant(a, b *matchTicket, now time.Time) (string, bool) {
+	variant := a.variant
+	if variant == matchAnyVariant {
+		variant = b.variant
+	} else if b.variant != matchAnyVariant && b.variant != a.variant {
+		return "", false
+	}
+	if math.Abs(a.rating-b.rating) > math.Min(a.band(now), b.band(now)) {
+		return "", false
+	}
+	if variant == matchAnyVariant {
+		variant = "standard"
+	}
+	return variant, true
+}
+
+// 対戦相手を組み、時間切れの待ちをAI戦に回す
+func (m *matchmaker) tick(now time.Time) {
+	m.mu.Lock()
+	type pair struct {
+		a, b    *matchTicket
+		variant string
+	}
+	var pairs []pair
+	var expired []*matchTicket
+
+	paired := make(map[*matchTicket]bool)
+	for i, a := range m.tickets {
+		if paired[a] {
+			continue
+		}
+		for _, b := range m.tickets[i+1:] {
+			if paired[b] {
+				continue
+			}
+			if variant, ok := matchVariant(a, b, now); ok {
+				pairs = append(pairs, pair{a, b, variant})
+				paired[a], paired[b] = true, true
+				break
+			}
+		}
+		if !paired[a] && now.Sub(a.joinedAt) >= MatchTimeout {
+			expired = append(expired, a)
+			paired[a] = true
+		}
+	}
+
+	kept := m.tickets[:0]
+	for _, t := range m.tickets {
+		if !paired[t] {
+			kept = append(kept, t)
+		}
+	}
+	m.tickets = kept
+	m.mu.Unlock()
+
+	for _, p := range pairs {
+		lobbyClients.setQueued(p.a.playerID, false)
+		lobbyClients.setQueued(p.b.playerID, false)
+		createMatch(p.a, p.b, p.variant)
+	}
+	for _, t := range expired {
+		lobbyClients.setQueued(t.playerID, false)
+		createBotMatch(t)
+	}
+}
+
+// AIの強さ（いまは1段階のみ）
+const botDifficultyStandard = "standard"
+
+// AIプレイヤーの作成を直列化する（同時に作成して重複させない）
+var botPlayerMu sync.Mutex
+
+// 強さごとに1人だけのAIプレイヤーを返す（なければ作成する）
+// @param difficulty AIの強さ
+// @return *model.Player AIプレイヤー
+// @return error 取得も作成もできなければエラー
+func botPlayer(difficulty string) (*model.Player, error) {
+	botPlayerMu.Lock()
+	defer botPlayerMu.Unlock()
+
+	id := "bot-" + difficulty
+	if bot, err := db.GetPlayerByID(id); err == nil {
+		// 非アクティブなゲストとして削除されないよう、使うたびに最終利用日時を更新する
+		db.TouchPlayer(id)
+		return bot, nil
+	}
+	bot := &model.Player{
+		ID:         id,
+		Name:       "CPU",
+		LastUsedAt: time.Now(),
+		IsBot:      true,
+	}
+	if err := db.CreatePlayer(bot); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// AIプレイヤーと対戦させる
+func createBotMatch(t *matchTicket) {
+	bot, err := botPlayer(botDifficultyStandard)
+	if err != nil {
+		log.Println("Failed to get bot player:", err)
+		t.client.push(map[string]string{"error": "failed to find a match"})
+		return
+	}
+	variant := t.variant
+	if variant == matchAnyVariant {
+		variant = lobby.VariantStandard
+	}
+	createMatch(t, &matchTicket{playerID: bot.ID, name: bot.Name, variant: variant}, variant)
+}
+
+// 先手をランダムに決めて対戦を開始する
+func createMatch(a, b *matchTicket, variant string) {
+	// 先手（Black = Player1）はランダムに決める
+	if rand.Intn(2) == 0 {
+		a, b = b, a
+	}
+
+	startMatch(a, b, variant)
+}
+
+// 色を決めた2人でルームを作成し、両プレイヤーに通知する
+// @param a 先手（Black = Player1）
+// @param b 後手（White = Player2）
+// @param variant 持ち時間のプリセット
+// @return error ルームを作成できなければエラー
+func startMatch(a, b *matchTicket, variant string) error {
+	room, err := Rooms.CreateMatchRoom(a.playerID, b.playerID, variant)
+	if err != nil {
+		log.Println("Failed to create match room:", err)
+		return err
+	}
+
+	vsAI := a.client == nil || b.client == nil
+	for _, side := range []struct {
+		self, opponent *matchTicket
+		color          int
+	}{{a, b, reversi.Black}, {b, a, reversi.White}} {
+		if side.self.client == nil {
+			continue
+		}
+		side.self.client.push(map[string]interface{}{
+			"type":      "match_found",
+			"roomID":    room.ID,
+			"yourColor": side.color,
+			"opponent":  side.opponent.name,
+			"variant":   variant,
+			"vsAI":      vsAI,
+		})
+	}
+	return nil
+}
+
+func init() {
+	go func() {
+		for now := range time.Tick(matchTickInterval) {
+			matchQueue.tick(now)
+		}
+	}()
+}
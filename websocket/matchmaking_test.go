@@ -0,0 +1,25 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/repository"
+	"testing"
+)
+
+func Test10_BotPlayerIsReused(t *testing.T) {
+	defer db.SetRepositories(repository.Repositories{
+		Players: db.Players, Rooms: db.Rooms, Games: db.Games,
+		Sessions: db.Sessions, Ratings: db.Ratings, Chats: db.Chats,
+	})
+	db.SetRepositories(repository.NewMemory())
+
+	first, err := botPlayer(botDifficultyStandard)
+	if err != nil || !first.IsBot {
+		t.Fatalf("Expected a bot player, got %+v, %v", first, err)
+	}
+	// 対局ごとに作らず、同じ強さなら同じプレイヤーを使う
+	second, err := botPlayer(botDifficultyStandard)
+	if err != nil || second.ID != first.ID {
+		t.Errorf("Expected the same bot player, got %+v, %v", second, err)
+	}
+}
@@ -0,0 +1,142 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"sort"
+)
+
+// ロビーに表示するプレイヤーの状態
+const (
+	presenceIdle       = "idle"
+	presenceInQueue    = "in_queue"
+	presencePlaying    = "playing"
+	presenceSpectating = "spectating"
+	presenceOffline    = "offline"
+)
+
+// PresenceInfo はオンラインのプレイヤー1人分の情報
+type PresenceInfo struct {
+	PlayerID string `json:"playerID"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+}
+
+// presenceEntry はプレイヤーごとの接続状況
+type presenceEntry struct {
+	name     string
+	lobby    int            // ロビー接続の数
+	queued   bool           // マッチング待ちかどうか
+	activity map[string]int // 対局・観戦中の接続の数（状態ごと）
+}
+
+// 接続状況から状態を決める（対局中 > 観戦中 > マッチング待ち > 待機中）
+// @return string 状態
+func (p *presenceEntry) status() string {
+	switch {
+	case !p.online():
+		return presenceOffline
+	case p.activity[presencePlaying] > 0:
+		return presencePlaying
+	case p.activity[presenceSpectating] > 0:
+		return presenceSpectating
+	case p.queued:
+		return presenceInQueue
+	default:
+		return presenceIdle
+	}
+}
+
+// ロビーかルームのどこかに接続していれば true
+func (p *presenceEntry) online() bool {
+	return p.lobby > 0 || len(p.activity) > 0
+}
+
+// presenceUpdate はハブのゴルーチンで適用する状態の変更
+type presenceUpdate struct {
+	playerID string
+	name     string
+	apply    func(p *presenceEntry)
+}
+
+// 状態を変更し、変わっていればロビーへ presence を配信する（ハブのゴルーチンで呼ぶ）
+func (h *lobbyHub) updatePresence(u presenceUpdate) {
+	p, ok := h.presence[u.playerID]
+	if !ok {
+		p = &presenceEntry{activity: make(map[string]int)}
+	}
+	if u.name != "" {
+		p.name = u.name
+	}
+	before := p.status()
+	u.apply(p)
+	after := p.status()
+
+	if p.online() {
+		h.presence[u.playerID] = p
+	} else {
+		delete(h.presence, u.playerID)
+	}
+	if before != after {
+		h.deliver(map[string]interface{}{
+			"type":     "presence",
+			"playerID": u.playerID,
+			"name":     p.name,
+			"status":   after,
+		})
+	}
+}
+
+// オンラインのプレイヤー一覧を名前順で返す（ハブのゴルーチンで呼ぶ）
+func (h *lobbyHub) online() []PresenceInfo {
+	players := make([]PresenceInfo, 0, len(h.presence))
+	for id, p := range h.presence {
+		players = append(players, PresenceInfo{PlayerID: id, Name: p.name, Status: p.status()})
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Name != players[j].Name {
+			return players[i].Name < players[j].Name
+		}
+		return players[i].PlayerID < players[j].PlayerID
+	})
+	return players
+}
+
+// マッチング待ちの開始・終了を記録する
+// @param playerID プレイヤー
+// @param queued 待ちに入ったなら true
+func (h *lobbyHub) setQueued(playerID string, queued bool) {
+	h.updates <- presenceUpdate{playerID: playerID, apply: func(p *presenceEntry) {
+		p.queued = queued
+	}}
+}
+
+// ルームへの接続を記録する
+// @param playerID プレイヤー
+// @param name 表示名
+// @param status presencePlaying または presenceSpectating
+func (h *lobbyHub) enterRoom(playerID, name, status string) {
+	h.updates <- presenceUpdate{playerID: playerID, name: name, apply: func(p *presenceEntry) {
+		p.activity[status]++
+	}}
+}
+
+// ルームからの切断を記録する
+// @param playerID プレイヤー
+// @param status enterRoom で指定した状態
+func (h *lobbyHub) leaveRoom(playerID, status string) {
+	h.updates <- presenceUpdate{playerID: playerID, apply: func(p *presenceEntry) {
+		if p.activity[status]--; p.activity[status] <= 0 {
+			delete(p.activity, status)
+		}
+	}}
+}
+
+// プレイヤーの表示名を返す
+// @param playerID プレイヤー
+// @return string 表示名（見つからなければ空）
+func playerName(playerID string) string {
+	if player, err := db.GetPlayerByID(playerID); err == nil {
+		return player.Name
+	}
+	return ""
+}
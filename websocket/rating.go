@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"be-binareversi/db"
+	"be-binareversi/libs/glicko2"
+	"be-binareversi/libs/reversi"
+	"be-binareversi/model"
+	"log"
+)
+
+// 対局結果を両プレイヤーのレーティングに反映する（AI戦は対象外）
+// @param roomID 対局したルーム
+// @param blackID 黒番のプレイヤー
+// @param whiteID 白番のプレイヤー
+// @param winner 勝者（Black=1, White=0, 引き分け=-1）
+func recordRatings(roomID, blackID, whiteID string, winner int) {
+	blackScore := 0.5
+	if winner == reversi.Black {
+		blackScore = 1
+	} else if winner == reversi.White {
+		blackScore = 0
+	}
+
+	err := db.SaveRatingResult([]string{blackID, whiteID}, func(players []*model.Player) []*model.RatingHistory {
+		return rateGame(roomID, players[0], players[1], blackScore)
+	})
+	if err != nil {
+		log.Println("Failed to save rating result:", err)
+	}
+}
+
+// 両プレイヤーのレーティングを対局結果で書き換え、履歴を返す
+// @param roomID 対局したルーム
+// @param black 黒番のプレイヤー
+// @param white 白番のプレイヤー
+// @param blackScore 黒番の得点（勝ち=1, 引き分け=0.5, 負け=0）
+// @return []*model.RatingHistory 両者の履歴（AI戦なら nil）
+func rateGame(roomID string, black, white *model.Player, blackScore float64) []*model.RatingHistory {
+	if black.IsBot || white.IsBot {
+		return nil
+	}
+
+	before := map[*model.Player]glicko2.Rating{
+		black: {Rating: black.Rating, RD: black.RatingRD, Volatility: black.Volatility},
+		white: {Rating: white.Rating, RD: white.RatingRD, Volatility: white.Volatility},
+	}
+	sides := []struct {
+		self, opponent *model.Player
+		score          float64
+	}{{black, white, blackScore}, {white, black, 1 - blackScore}}
+
+	var history []*model.RatingHistory
+	for _, side := range sides {
+		updated := glicko2.Update(before[side.self], []glicko2.Result{
+			{Opponent: before[side.opponent], Score: side.score},
+		})
+		history = append(history, &model.RatingHistory{
+			PlayerID:   side.self.ID,
+			RoomID:     roomID,
+			OpponentID: side.opponent.ID,
+			Score:      side.score,
+			Rating:     updated.Rating,
+			RatingRD:   updated.RD,
+			Volatility: updated.Volatility,
+			Delta:      updated.Rating - side.self.Rating,
+		})
+		side.self.Rating = updated.Rating
+		side.self.RatingRD = updated.RD
+		side.self.Volatility = updated.Volatility
+		side.self.RatedGames++
+	}
+	return history
+}
@@ -1,11 +1,13 @@
 package websocket
 
 import (
+	"be-binareversi/auth"
 	"net/http"
 
 	"github.com/gorilla/websocket"
 )
 
 var Upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{auth.Subprotocol}, // トークンをサブプロトコルで渡された場合に応答する
 }